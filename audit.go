@@ -0,0 +1,485 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/packethost/cacher/hardware"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/peer"
+)
+
+// auditEntry records a single accepted mutation.
+type auditEntry struct {
+	Time   time.Time       `json:"time"`
+	Method string          `json:"method"`
+	ID     string          `json:"id"`
+	Caller string          `json:"caller,omitempty"`
+	Peer   string          `json:"peer,omitempty"`
+	Old    json.RawMessage `json:"old,omitempty"`
+	New    json.RawMessage `json:"new"`
+	Diff   []fieldChange   `json:"diff"`
+	// hardware.Written.Seq of the mutation, the order the memory store made the mutations in, 0 with other stores
+	Seq uint64 `json:"seq,omitempty"`
+}
+
+// fieldChange is a single field-level difference between the old and new documents, Path is a JSON pointer.
+type fieldChange struct {
+	Op   string      `json:"op"`
+	Path string      `json:"path"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+// newAuditEntry builds the audit entry for a mutation of id from before to after made by the caller in ctx.
+func newAuditEntry(ctx context.Context, method, id, before, after string) (auditEntry, error) {
+	e := auditEntry{
+		Time:   time.Now().UTC(),
+		Method: method,
+		ID:     id,
+		Caller: callerName(ctx),
+		New:    json.RawMessage(after),
+	}
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		e.Peer = p.Addr.String()
+	}
+
+	var o, n interface{}
+
+	if before != "" {
+		e.Old = json.RawMessage(before)
+		if err := json.Unmarshal([]byte(before), &o); err != nil {
+			return e, errors.Wrap(err, "decode old json")
+		}
+	} else {
+		o = map[string]interface{}{}
+	}
+
+	if err := json.Unmarshal([]byte(after), &n); err != nil {
+		return e, errors.Wrap(err, "decode new json")
+	}
+
+	e.Diff = jsonDiff("", o, n, []fieldChange{})
+
+	return e, nil
+}
+
+// jsonDiff appends the changes needed to turn o into n to changes, objects and arrays are compared element by element.
+func jsonDiff(path string, o, n interface{}, changes []fieldChange) []fieldChange {
+	switch ov := o.(type) {
+	case map[string]interface{}:
+		nv, ok := n.(map[string]interface{})
+		if !ok {
+			break
+		}
+
+		keys := make([]string, 0, len(ov)+len(nv))
+		for k := range ov {
+			keys = append(keys, k)
+		}
+
+		for k := range nv {
+			if _, ok := ov[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			p := path + "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(k)
+			ovk, inOld := ov[k]
+			nvk, inNew := nv[k]
+
+			switch {
+			case !inOld:
+				changes = append(changes, fieldChange{Op: "add", Path: p, New: nvk})
+			case !inNew:
+				changes = append(changes, fieldChange{Op: "remove", Path: p, Old: ovk})
+			default:
+				changes = jsonDiff(p, ovk, nvk, changes)
+			}
+		}
+
+		return changes
+	case []interface{}:
+		nv, ok := n.([]interface{})
+		if !ok {
+			break
+		}
+
+		for i := 0; i < len(ov) || i < len(nv); i++ {
+			p := path + "/" + strconv.Itoa(i)
+
+			switch {
+			case i >= len(ov):
+				changes = append(changes, fieldChange{Op: "add", Path: p, New: nv[i]})
+			case i >= len(nv):
+				changes = append(changes, fieldChange{Op: "remove", Path: p, Old: ov[i]})
+			default:
+				changes = jsonDiff(p, ov[i], nv[i], changes)
+			}
+		}
+
+		return changes
+	}
+
+	if !reflect.DeepEqual(o, n) {
+		changes = append(changes, fieldChange{Op: "replace", Path: path, Old: o, New: n})
+	}
+
+	return changes
+}
+
+// audit records a mutation in the audit log, if enabled.
+// Failures are logged and counted but do not fail the mutation, which has already been applied.
+func (s *server) audit(ctx context.Context, method string, w hardware.Written, after string) {
+	if s.auditLog == nil {
+		return
+	}
+
+	e, err := newAuditEntry(ctx, method, w.ID, w.Old, after)
+	if err == nil {
+		e.Seq = w.Seq
+		err = s.auditLog.Write(e)
+	} else {
+		s.auditLog.skip(w.Seq)
+	}
+
+	if err != nil {
+		auditErrors.Inc()
+		logger.With("id", w.ID).Error(errors.Wrap(err, "audit"))
+	}
+}
+
+// auditOrderWait is how long an entry waits for the ones of the mutations made before it to be written, in case one of
+// them never is.
+const auditOrderWait = time.Second
+
+// auditLog appends entries as JSON lines to a local file, rotating it once it grows past maxSize bytes.
+// Up to maxBackups rotated files are kept as file.1 (newest) through file.N (oldest).
+// Entries with a Seq are written in its order, as the mutations they record are made in that order under the lock of
+// the store but audited after it is released.
+type auditLog struct {
+	file       string
+	maxSize    int64
+	maxBackups int
+
+	mu   sync.Mutex
+	f    *os.File // nil if the file could not be reopened after rotating it
+	size int64
+
+	next     uint64        // Seq of the next entry to write
+	advanced chan struct{} // closed once next advances
+}
+
+func newAuditLog(file string, maxSize int64, maxBackups int) (*auditLog, error) {
+	l := &auditLog{file: file, maxSize: maxSize, maxBackups: maxBackups, next: 1, advanced: make(chan struct{})}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+func (l *auditLog) open() error {
+	f, err := os.OpenFile(l.file, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return errors.Wrap(err, "open audit log")
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+
+		return errors.Wrap(err, "stat audit log")
+	}
+
+	l.f = f
+	l.size = fi.Size()
+
+	return nil
+}
+
+func (l *auditLog) backup(n int) string {
+	return fmt.Sprintf("%s.%d", l.file, n)
+}
+
+// rotate moves the current file to the first backup, shifting the backups, and opens a new one.
+// If the file can't be rotated it is reopened, so the next entries are still appended to it.
+func (l *auditLog) rotate() error {
+	err := l.f.Close()
+	l.f = nil
+
+	if err != nil {
+		err = errors.Wrap(err, "close audit log")
+	} else {
+		err = l.shift()
+	}
+
+	if oerr := l.open(); err == nil {
+		err = oerr
+	}
+
+	return err
+}
+
+// shift moves the current file to the first backup, shifting the backups, or removes it if none are kept.
+func (l *auditLog) shift() error {
+	if l.maxBackups < 1 {
+		return errors.Wrap(os.Remove(l.file), "remove audit log")
+	}
+
+	for n := l.maxBackups - 1; n > 0; n-- {
+		if err := os.Rename(l.backup(n), l.backup(n+1)); err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "rotate audit log")
+		}
+	}
+
+	return errors.Wrap(os.Rename(l.file, l.backup(1)), "rotate audit log")
+}
+
+// Write appends e to the log, once the entries with a lower Seq are written.
+func (l *auditLog) Write(e auditEntry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		l.skip(e.Seq)
+
+		return errors.Wrap(err, "encode audit entry")
+	}
+
+	b = append(b, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.wait(e.Seq)
+	defer l.advance(e.Seq)
+
+	if l.f == nil {
+		if err := l.open(); err != nil {
+			return err
+		}
+	}
+
+	var rerr error
+	if l.size > 0 && l.size+int64(len(b)) > l.maxSize {
+		rerr = l.rotate()
+		if l.f == nil {
+			return rerr
+		}
+	}
+
+	n, err := l.f.Write(b)
+	l.size += int64(n)
+
+	if rerr != nil {
+		return rerr
+	}
+
+	return errors.Wrap(err, "write audit entry")
+}
+
+// skip lets the entries after seq be written without the one for seq, which won't be.
+func (l *auditLog) skip(seq uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.wait(seq)
+	l.advance(seq)
+}
+
+// wait waits until the entries before seq are written, or for auditOrderWait at most.
+// Must be called with the lock held, which is released while waiting.
+func (l *auditLog) wait(seq uint64) {
+	if seq == 0 {
+		return
+	}
+
+	timeout := time.NewTimer(auditOrderWait)
+	defer timeout.Stop()
+
+	for seq > l.next {
+		advanced := l.advanced
+
+		l.mu.Unlock()
+		select {
+		case <-advanced:
+		case <-timeout.C:
+			l.mu.Lock()
+
+			return
+		}
+		l.mu.Lock()
+	}
+}
+
+// advance records the entry for seq as written.
+// Must be called with the lock held.
+func (l *auditLog) advance(seq uint64) {
+	if seq < l.next {
+		return
+	}
+
+	l.next = seq + 1
+	close(l.advanced)
+	l.advanced = make(chan struct{})
+}
+
+// auditQueryMaxLimit caps the limit of Query, which keeps that many entries in memory.
+const auditQueryMaxLimit = 10000
+
+// auditFile is a log file opened for reading along with its size when it was opened, as it may still be appended to.
+type auditFile struct {
+	*os.File
+	size int64
+}
+
+// snapshot opens the log files, oldest first, so they can be read without holding up writes.
+// Rotations only rename or remove the files, which doesn't affect the ones already opened.
+func (l *auditLog) snapshot() ([]auditFile, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	files := make([]auditFile, 0, l.maxBackups+1)
+
+	closeAll := func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}
+
+	for n := l.maxBackups; n > 0; n-- {
+		f, err := os.Open(l.backup(n))
+		if os.IsNotExist(err) {
+			continue
+		}
+
+		if err != nil {
+			closeAll()
+
+			return nil, errors.Wrap(err, "open audit log")
+		}
+
+		fi, err := f.Stat()
+		if err != nil {
+			f.Close()
+			closeAll()
+
+			return nil, errors.Wrap(err, "stat audit log")
+		}
+
+		files = append(files, auditFile{File: f, size: fi.Size()})
+	}
+
+	f, err := os.Open(l.file)
+	if err != nil {
+		closeAll()
+
+		return nil, errors.Wrap(err, "open audit log")
+	}
+
+	return append(files, auditFile{File: f, size: l.size}), nil
+}
+
+// Query calls fn with the entries for id (all ids if empty) recorded at or after since, oldest first, and stops at the
+// first error it returns.
+// If limit > 0 only the most recent limit entries, at most auditQueryMaxLimit, are passed to fn once every file is
+// read, otherwise the entries are passed as they are read.
+// Entries written after Query is called are not seen, and writes don't wait for it.
+func (l *auditLog) Query(id string, since time.Time, limit int, fn func(auditEntry) error) error {
+	files, err := l.snapshot()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}()
+
+	if limit > auditQueryMaxLimit {
+		limit = auditQueryMaxLimit
+	}
+
+	// the most recent entries, last[next] being the oldest once it is full
+	var (
+		last []auditEntry
+		next int
+	)
+
+	match := fn
+	if limit > 0 {
+		match = func(e auditEntry) error {
+			if len(last) < limit {
+				last = append(last, e)
+			} else {
+				last[next] = e
+				next = (next + 1) % limit
+			}
+
+			return nil
+		}
+	}
+
+	for _, f := range files {
+		if err := scanAudit(f, id, since, match); err != nil {
+			return err
+		}
+	}
+
+	for i := range last {
+		if err := fn(last[(next+i)%len(last)]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// scanAudit calls fn with the entries of f for id (all ids if empty) recorded at or after since.
+func scanAudit(f auditFile, id string, since time.Time, fn func(auditEntry) error) error {
+	s := bufio.NewScanner(io.LimitReader(f, f.size))
+	s.Buffer(nil, 16*1024*1024)
+
+	for s.Scan() {
+		var e auditEntry
+		if err := json.Unmarshal(s.Bytes(), &e); err != nil {
+			return errors.Wrapf(err, "decode audit entry in %s", f.Name())
+		}
+
+		if (id != "" && e.ID != id) || e.Time.Before(since) {
+			continue
+		}
+
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+
+	return errors.Wrapf(s.Err(), "read %s", f.Name())
+}
+
+// Close closes the current log file.
+func (l *auditLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.f == nil {
+		return nil
+	}
+
+	return l.f.Close()
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/peer"
+)
+
+func TestJSONDiff(t *testing.T) {
+	assert := require.New(t)
+
+	e, err := newAuditEntry(context.Background(), "Push", "id",
+		`{"id":"id","state":"provisioning","a/b":1,"ip_addresses":[{"address":"10.0.0.1"},{"address":"10.0.0.2"}],"old":true}`,
+		`{"id":"id","state":"in_use","a/b":1,"ip_addresses":[{"address":"10.0.0.3"}],"new":{"x":1}}`,
+	)
+	assert.NoError(err)
+	assert.Equal([]fieldChange{
+		{Op: "replace", Path: "/ip_addresses/0/address", Old: "10.0.0.1", New: "10.0.0.3"},
+		{Op: "remove", Path: "/ip_addresses/1", Old: map[string]interface{}{"address": "10.0.0.2"}},
+		{Op: "add", Path: "/new", New: map[string]interface{}{"x": float64(1)}},
+		{Op: "remove", Path: "/old", Old: true},
+		{Op: "replace", Path: "/state", Old: "provisioning", New: "in_use"},
+	}, e.Diff)
+
+	// a new record shows up as all fields being added
+	e, err = newAuditEntry(context.Background(), "Push", "id", "", `{"id":"id"}`)
+	assert.NoError(err)
+	assert.Nil(e.Old)
+	assert.Equal([]fieldChange{{Op: "add", Path: "/id", New: "id"}}, e.Diff)
+}
+
+func TestAuditLog(t *testing.T) {
+	assert := require.New(t)
+	f := filepath.Join(t.TempDir(), "audit.log")
+
+	// small enough that every entry rotates the file
+	l, err := newAuditLog(f, 10, 2)
+	assert.NoError(err)
+
+	ctx := peer.NewContext(withCaller(context.Background(), "sync"), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 1234}})
+
+	query := func(id string, since time.Time, limit int) []auditEntry {
+		var entries []auditEntry
+		assert.NoError(l.Query(id, since, limit, func(e auditEntry) error {
+			entries = append(entries, e)
+
+			return nil
+		}))
+
+		return entries
+	}
+
+	start := time.Now().Add(-time.Second)
+	for _, id := range []string{"a", "b", "a", "c"} {
+		e, err := newAuditEntry(ctx, "Push", id, "", `{"id":"`+id+`"}`)
+		assert.NoError(err)
+		assert.NoError(l.Write(e))
+	}
+
+	// the oldest entry has been rotated away
+	entries := query("", time.Time{}, 0)
+	assert.Len(entries, 3)
+	assert.Equal("b", entries[0].ID)
+	assert.Equal("c", entries[2].ID)
+	assert.Equal("sync", entries[0].Caller)
+	assert.Equal("10.0.0.1:1234", entries[0].Peer)
+
+	entries = query("a", start, 0)
+	assert.Len(entries, 1)
+
+	entries = query("", time.Now().Add(time.Hour), 0)
+	assert.Empty(entries)
+
+	entries = query("", time.Time{}, 1)
+	assert.Len(entries, 1)
+	assert.Equal("c", entries[0].ID)
+
+	assert.NoError(l.Close())
+
+	// reopening appends to the existing file
+	l, err = newAuditLog(f, 1<<20, 2)
+	assert.NoError(err)
+	e, err := newAuditEntry(ctx, "Push", "d", "", `{"id":"d"}`)
+	assert.NoError(err)
+	assert.NoError(l.Write(e))
+	entries = query("", time.Time{}, 0)
+	assert.Len(entries, 4)
+
+	entries = query("", time.Time{}, 2)
+	assert.Len(entries, 2)
+	assert.Equal("c", entries[0].ID)
+	assert.Equal("d", entries[1].ID)
+
+	// writes go on while the log is read, the entries they add are not seen
+	n := 0
+	assert.NoError(l.Query("", time.Time{}, 0, func(auditEntry) error {
+		n++
+
+		return l.Write(e)
+	}))
+	assert.Equal(4, n)
+	assert.Len(query("", time.Time{}, 0), 8)
+
+	assert.ErrorIs(l.Query("", time.Time{}, 0, func(auditEntry) error { return context.Canceled }), context.Canceled)
+	assert.NoError(l.Close())
+}
+
+func TestAuditLogOrder(t *testing.T) {
+	assert := require.New(t)
+
+	l, err := newAuditLog(filepath.Join(t.TempDir(), "audit.log"), 1<<20, 2)
+	assert.NoError(err)
+	defer l.Close()
+
+	// the entry of the second mutation waits for the one of the first
+	done := make(chan error)
+	go func() {
+		done <- l.Write(auditEntry{ID: "a", Seq: 2})
+	}()
+
+	assert.NoError(l.Write(auditEntry{ID: "a", Seq: 1}))
+	assert.NoError(<-done)
+
+	// entries that won't be written don't hold up the next ones, and neither do the ones without a Seq
+	l.skip(3)
+	start := time.Now()
+	assert.NoError(l.Write(auditEntry{ID: "a", Seq: 4}))
+	assert.NoError(l.Write(auditEntry{ID: "b"}))
+	assert.Less(time.Since(start), auditOrderWait)
+
+	// nor do the ones that never come, for long
+	assert.NoError(l.Write(auditEntry{ID: "a", Seq: 6}))
+	assert.NoError(l.Write(auditEntry{ID: "a", Seq: 5}))
+
+	var seqs []uint64
+	assert.NoError(l.Query("", time.Time{}, 0, func(e auditEntry) error {
+		seqs = append(seqs, e.Seq)
+
+		return nil
+	}))
+	assert.Equal([]uint64{1, 2, 4, 0, 6, 5}, seqs)
+}
+
+func TestAuditLogRotateError(t *testing.T) {
+	assert := require.New(t)
+	f := filepath.Join(t.TempDir(), "audit.log")
+
+	l, err := newAuditLog(f, 10, 2)
+	assert.NoError(err)
+	defer l.Close()
+
+	assert.NoError(l.Write(auditEntry{ID: "a"}))
+
+	// a directory in the way of the second backup keeps the first, and so the file, from being rotated
+	assert.NoError(os.WriteFile(f+".1", nil, 0o600))
+	assert.NoError(os.MkdirAll(filepath.Join(f+".2", "x"), 0o700))
+	assert.Error(l.Write(auditEntry{ID: "b"}))
+
+	// the file was reopened, and the entry still appended to it
+	assert.NoError(os.RemoveAll(f + ".2"))
+	assert.NoError(os.Remove(f + ".1"))
+	assert.NoError(l.Write(auditEntry{ID: "c"}))
+
+	var ids []string
+	assert.NoError(l.Query("", time.Time{}, 0, func(e auditEntry) error {
+		ids = append(ids, e.ID)
+
+		return nil
+	}))
+	assert.Equal([]string{"a", "b", "c"}, ids)
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const cacherServicePrefix = "/cacher.Cacher/"
+
+// authzRule grants the listed methods to any client whose certificate matches one of the subjects.
+// A subject or method of "*" matches everything.
+type authzRule struct {
+	Subjects []string `json:"subjects"`
+	Methods  []string `json:"methods"`
+}
+
+// authzPolicy maps client certificate subjects/SANs to the Cacher methods they may call.
+type authzPolicy struct {
+	Rules []authzRule `json:"rules"`
+}
+
+func loadAuthzPolicy(file string) (*authzPolicy, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "read authz policy")
+	}
+
+	p := &authzPolicy{}
+	if err := json.Unmarshal(b, p); err != nil {
+		return nil, errors.Wrap(err, "decode authz policy")
+	}
+
+	for i, r := range p.Rules {
+		if len(r.Subjects) == 0 || len(r.Methods) == 0 {
+			return nil, errors.Errorf("authz policy rule %d needs at least one subject and one method", i)
+		}
+	}
+
+	return p, nil
+}
+
+// allowed reports whether any of the names is granted method.
+func (p *authzPolicy) allowed(names []string, method string) bool {
+	for _, r := range p.Rules {
+		if matchAny(r.Methods, method) && matchAnyOf(r.Subjects, names) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchAny(patterns []string, v string) bool {
+	for _, p := range patterns {
+		if p == "*" || p == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchAnyOf(patterns, vs []string) bool {
+	for _, v := range vs {
+		if matchAny(patterns, v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// certNames returns every name a client certificate can be matched by: the subject DN, its CN and all SANs.
+func certNames(cert *x509.Certificate) []string {
+	names := []string{cert.Subject.String()}
+	if cert.Subject.CommonName != "" {
+		names = append(names, cert.Subject.CommonName)
+	}
+
+	names = append(names, cert.DNSNames...)
+	names = append(names, cert.EmailAddresses...)
+
+	for _, ip := range cert.IPAddresses {
+		names = append(names, ip.String())
+	}
+
+	for _, u := range cert.URIs {
+		names = append(names, u.String())
+	}
+
+	return names
+}
+
+// peerCert returns the verified client certificate of the caller, if any.
+func peerCert(ctx context.Context) *x509.Certificate {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return nil
+	}
+
+	return info.State.VerifiedChains[0][0]
+}
+
+type callerKey struct{}
+
+// withCaller records the authenticated caller's name in ctx.
+func withCaller(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, callerKey{}, name)
+}
+
+// callerName returns the authenticated caller's name, or "" if the call was not authenticated.
+func callerName(ctx context.Context) string {
+	name, _ := ctx.Value(callerKey{}).(string)
+
+	return name
+}
+
+// certName is the name used to identify a client certificate in logs and metrics.
+func certName(cert *x509.Certificate) string {
+	if cert.Subject.CommonName != "" {
+		return cert.Subject.CommonName
+	}
+
+	return cert.Subject.String()
+}
+
+// authz authenticates callers of Cacher methods with a bearer token or a verified client certificate and
+// checks they may call the method.
+// With mtls set and a nil policy any client that presented a verified certificate is allowed.
+type authz struct {
+	mtls   bool
+	policy *authzPolicy
+	tokens *tokenStore
+}
+
+func (a *authz) authorize(ctx context.Context, fullMethod string) (context.Context, error) {
+	// only the cacher service is subject to the policy, health checks et al. are left alone
+	if !strings.HasPrefix(fullMethod, cacherServicePrefix) {
+		return ctx, nil
+	}
+
+	method := path.Base(fullMethod)
+
+	if a.tokens != nil {
+		if tok := bearerToken(ctx); tok != "" {
+			t, ok := a.tokens.lookup(tok)
+			if !ok {
+				return ctx, a.deny(ctx, method, "", "invalid token")
+			}
+
+			if !t.allows(method) {
+				return ctx, a.deny(ctx, method, t.Name, "token lacks the required scope")
+			}
+
+			// token names come from the token file, so there are only so many
+			return a.allow(ctx, method, t.Name, t.Name), nil
+		}
+	}
+
+	if a.mtls {
+		cert := peerCert(ctx)
+		if cert == nil {
+			return ctx, a.deny(ctx, method, "", "no verified client certificate")
+		}
+
+		if a.policy != nil && !a.policy.allowed(certNames(cert), method) {
+			return ctx, a.deny(ctx, method, certName(cert), "method not allowed for client")
+		}
+
+		return a.allow(ctx, method, certName(cert), a.certLabel(cert)), nil
+	}
+
+	return ctx, a.deny(ctx, method, "", "missing bearer token")
+}
+
+// otherCaller labels the callers not named in the configuration in auth_requests_total.
+const otherCaller = "other"
+
+// certLabel returns the caller label of cert in auth_requests_total: the first subject of the policy it has as a name,
+// otherCaller if none does, so any client with a verified certificate can't add labels as it pleases.
+func (a *authz) certLabel(cert *x509.Certificate) string {
+	if a.policy == nil {
+		return otherCaller
+	}
+
+	names := certNames(cert)
+	for _, r := range a.policy.Rules {
+		for _, s := range r.Subjects {
+			if s != "*" && matchAny(names, s) {
+				return s
+			}
+		}
+	}
+
+	return otherCaller
+}
+
+// allow records the caller in the logs, spans and metrics, with label as the caller label of the latter, and in ctx.
+func (a *authz) allow(ctx context.Context, method, name, label string) context.Context {
+	authRequests.With(prometheus.Labels{"method": method, "caller": label}).Inc()
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("caller", name))
+	// the logging interceptor runs first, fields added to its logger show in the line it logs once the call is done
+	ctxzap.AddFields(ctx, zap.String("caller", name))
+
+	return withCaller(ctx, name)
+}
+
+func (a *authz) deny(ctx context.Context, method, caller, reason string) error {
+	authDenied.With(prometheus.Labels{"method": method}).Inc()
+	logger.With("method", method, "caller", caller, "reason", reason).Info("permission denied")
+
+	if caller != "" {
+		ctxzap.AddFields(ctx, zap.String("caller", caller))
+	}
+
+	return status.Errorf(codes.PermissionDenied, "%s: %s", method, reason)
+}
+
+func (a *authz) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := a.authorize(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (a *authz) streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := a.authorize(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, &callerStream{ServerStream: ss, ctx: ctx})
+}
+
+// callerStream carries the authorized context into stream handlers.
+type callerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *callerStream) Context() context.Context {
+	return s.ctx
+}
+
+// mTLSConfig builds a server config verifying client certificates against the CAs in caPEM.
+// Clients must present one unless optional, as when bearer tokens are also accepted, in which case authorize rejects
+// the calls with neither.
+func mTLSConfig(certPEM, keyPEM, caPEM []byte, optional bool) (*tls.Config, error) {
+	kp, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse tls key pair")
+	}
+
+	cp := x509.NewCertPool()
+	if !cp.AppendCertsFromPEM(caPEM) {
+		return nil, errors.New("parse client ca")
+	}
+
+	clientAuth := tls.RequireAndVerifyClientCert
+	if optional {
+		clientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{kp},
+		ClientAuth:   clientAuth,
+		ClientCAs:    cp,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func peerContext(cert *x509.Certificate) context.Context {
+	info := credentials.TLSInfo{}
+	if cert != nil {
+		info.State = tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+	}
+
+	return peer.NewContext(context.Background(), &peer.Peer{AuthInfo: info})
+}
+
+func TestLoadAuthzPolicy(t *testing.T) {
+	assert := require.New(t)
+	dir := t.TempDir()
+
+	for _, test := range []struct {
+		policy string
+		err    bool
+	}{
+		{policy: `{"rules":[{"subjects":["boots"],"methods":["ByMAC"]}]}`},
+		{policy: `{"rules":[{"subjects":["boots"]}]}`, err: true},
+		{policy: `{"rules":[{"methods":["ByMAC"]}]}`, err: true},
+		{policy: `{"rules":`, err: true},
+	} {
+		f := filepath.Join(dir, "policy.json")
+		assert.NoError(os.WriteFile(f, []byte(test.policy), 0o600))
+
+		p, err := loadAuthzPolicy(f)
+		if test.err {
+			assert.Error(err, test.policy)
+			assert.Nil(p)
+		} else {
+			assert.NoError(err, test.policy)
+			assert.NotNil(p)
+		}
+	}
+
+	_, err := loadAuthzPolicy(filepath.Join(dir, "missing.json"))
+	assert.Error(err)
+}
+
+func TestAuthorize(t *testing.T) {
+	assert := require.New(t)
+
+	spiffe, err := url.Parse("spiffe://packet.net/api-sync")
+	assert.NoError(err)
+
+	boots := &x509.Certificate{Subject: pkix.Name{CommonName: "boots"}}
+	apiSync := &x509.Certificate{Subject: pkix.Name{CommonName: "sync"}, URIs: []*url.URL{spiffe}}
+	other := &x509.Certificate{Subject: pkix.Name{CommonName: "other"}, DNSNames: []string{"other.packet.net"}}
+
+	a := &authz{mtls: true, policy: &authzPolicy{Rules: []authzRule{
+		{Subjects: []string{"boots"}, Methods: []string{"ByMAC", "ByIP", "ByID", "All", "Watch"}},
+		{Subjects: []string{"spiffe://packet.net/api-sync"}, Methods: []string{"Push"}},
+		{Subjects: []string{"*"}, Methods: []string{"ByID"}},
+	}}}
+
+	for _, test := range []struct {
+		cert   *x509.Certificate
+		method string
+		ok     bool
+	}{
+		{cert: boots, method: "/cacher.Cacher/ByMAC", ok: true},
+		{cert: boots, method: "/cacher.Cacher/Push"},
+		{cert: apiSync, method: "/cacher.Cacher/Push", ok: true},
+		{cert: apiSync, method: "/cacher.Cacher/All"},
+		{cert: other, method: "/cacher.Cacher/ByID", ok: true},
+		{cert: other, method: "/cacher.Cacher/ByMAC"},
+		{cert: nil, method: "/cacher.Cacher/ByID"},
+		{cert: nil, method: "/grpc.health.v1.Health/Check", ok: true},
+	} {
+		_, err := a.authorize(peerContext(test.cert), test.method)
+		if test.ok {
+			assert.NoError(err, test.method)
+		} else {
+			assert.Equal(codes.PermissionDenied, status.Code(err), test.method)
+		}
+	}
+
+	// the caller label only takes the subjects named in the policy
+	for _, test := range []struct {
+		cert  *x509.Certificate
+		label string
+	}{
+		{cert: boots, label: "boots"},
+		{cert: apiSync, label: "spiffe://packet.net/api-sync"},
+		{cert: other, label: otherCaller},
+	} {
+		labels := prometheus.Labels{"method": "ByID", "caller": test.label}
+		allowed := testutil.ToFloat64(authRequests.With(labels))
+		_, err = a.authorize(peerContext(test.cert), "/cacher.Cacher/ByID")
+		assert.NoError(err)
+		assert.Equal(allowed+1, testutil.ToFloat64(authRequests.With(labels)), test.label)
+	}
+
+	denied := testutil.ToFloat64(authDenied.With(prometheus.Labels{"method": "Push"}))
+	_, err = a.authorize(peerContext(boots), "/cacher.Cacher/Push")
+	assert.Error(err)
+	assert.Equal(denied+1, testutil.ToFloat64(authDenied.With(prometheus.Labels{"method": "Push"})))
+
+	// the caller shows in the line the logging interceptor logs once the call is done, denied or not
+	core, logs := observer.New(zapcore.InfoLevel)
+	ctx := ctxzap.ToContext(peerContext(boots), zap.New(core))
+	_, err = a.authorize(ctx, "/cacher.Cacher/Push")
+	assert.Error(err)
+	ctxzap.Extract(ctx).Info("finished unary call")
+	assert.Equal("boots", logs.All()[0].ContextMap()["caller"])
+
+	// without a policy any verified client is allowed
+	a = &authz{mtls: true}
+	ctx, err = a.authorize(peerContext(other), "/cacher.Cacher/Push")
+	assert.NoError(err)
+	assert.Equal("other", callerName(ctx))
+	_, err = a.authorize(peerContext(nil), "/cacher.Cacher/Push")
+	assert.Error(err)
+}
+
+func TestMTLSConfig(t *testing.T) {
+	assert := require.New(t)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "cacher"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	assert.NoError(err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(err)
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	cfg, err := mTLSConfig(certPEM, keyPEM, certPEM, false)
+	assert.NoError(err)
+	assert.Equal(tls.RequireAndVerifyClientCert, cfg.ClientAuth)
+
+	// token-only clients get through the handshake, the certificate is still verified if given
+	cfg, err = mTLSConfig(certPEM, keyPEM, certPEM, true)
+	assert.NoError(err)
+	assert.Equal(tls.VerifyClientCertIfGiven, cfg.ClientAuth)
+
+	_, err = mTLSConfig(certPEM, keyPEM, []byte("not a ca"), true)
+	assert.Error(err)
+}
+
+func TestAuthorizeTokenOrCert(t *testing.T) {
+	assert := require.New(t)
+	f := filepath.Join(t.TempDir(), "tokens.json")
+	writeTokens(t, f, `{"tokens":[{"name":"tink","token":"t1","scopes":["read"]}]}`)
+
+	s, err := newTokenStore(f)
+	assert.NoError(err)
+
+	boots := &x509.Certificate{Subject: pkix.Name{CommonName: "boots"}}
+	a := &authz{mtls: true, tokens: s, policy: &authzPolicy{Rules: []authzRule{
+		{Subjects: []string{"boots"}, Methods: []string{"ByMAC"}},
+	}}}
+
+	// a client without a certificate uses its token
+	ctx := metadata.NewIncomingContext(peerContext(nil), metadata.Pairs("authorization", "Bearer t1"))
+	ctx, err = a.authorize(ctx, "/cacher.Cacher/ByMAC")
+	assert.NoError(err)
+	assert.Equal("tink", callerName(ctx))
+
+	// one without a token falls back to its certificate
+	ctx, err = a.authorize(peerContext(boots), "/cacher.Cacher/ByMAC")
+	assert.NoError(err)
+	assert.Equal("boots", callerName(ctx))
+
+	_, err = a.authorize(peerContext(boots), "/cacher.Cacher/Push")
+	assert.Equal(codes.PermissionDenied, status.Code(err))
+
+	// and one with neither is refused
+	_, err = a.authorize(peerContext(nil), "/cacher.Cacher/ByMAC")
+	assert.Equal(codes.PermissionDenied, status.Code(err))
+}
@@ -1,6 +1,8 @@
 package client
 
 import (
+	"context"
+	"crypto/tls"
 	"crypto/x509"
 	"fmt"
 	"io"
@@ -16,6 +18,9 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+
+	// registering gzip lets cacher compress large responses such as All
+	_ "google.golang.org/grpc/encoding/gzip"
 )
 
 func connect(facility string) (*grpc.ClientConn, error) {
@@ -53,8 +58,21 @@ func connect(facility string) (*grpc.ClientConn, error) {
 		if !ok {
 			return nil, errors.New("parsing cert")
 		}
-		creds := credentials.NewClientTLSFromCert(cp, "")
-		dialOpts = append(dialOpts, grpc.WithTransportCredentials(creds))
+		tlsConfig := &tls.Config{RootCAs: cp, MinVersion: tls.VersionTLS12}
+
+		// present a client certificate if cacher requires mutual TLS
+		if cert, key := env.Get("CACHER_TLS_CLIENT_CERT"), env.Get("CACHER_TLS_CLIENT_KEY"); cert != "" && key != "" {
+			kp, err := tls.X509KeyPair([]byte(cert), []byte(key))
+			if err != nil {
+				return nil, errors.Wrap(err, "parse client cert")
+			}
+			tlsConfig.Certificates = []tls.Certificate{kp}
+		}
+		dialOpts = append(dialOpts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	}
+
+	if tok := env.Get("CACHER_AUTH_TOKEN"); tok != "" {
+		dialOpts = append(dialOpts, grpc.WithPerRPCCredentials(bearerToken{token: tok, secure: useTLS}))
 	}
 
 	var err error
@@ -73,13 +91,28 @@ func connect(facility string) (*grpc.ClientConn, error) {
 	return conn, nil
 }
 
+// bearerToken sends a static token in the authorization metadata of every call.
+type bearerToken struct {
+	token  string
+	secure bool
+}
+
+func (t bearerToken) GetRequestMetadata(_ context.Context, _ ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + t.token}, nil
+}
+
+func (t bearerToken) RequireTransportSecurity() bool {
+	return t.secure
+}
+
 type CacherClient struct {
 	cacher.CacherClient
 	Conn *grpc.ClientConn
 }
 
 // New returns a new cacher client for the requested facility.
-// It respects the following environment variables: CACHER_USE_TLS, CACHER_CERT_URL, and CACHER_GRPC_AUTHORITY.
+// It respects the following environment variables: CACHER_USE_TLS, CACHER_CERT_URL, CACHER_GRPC_AUTHORITY,
+// CACHER_TLS_CLIENT_CERT, CACHER_TLS_CLIENT_KEY and CACHER_AUTH_TOKEN.
 func New(facility string) (CacherClient, error) {
 	conn, err := connect(facility)
 	if err != nil {
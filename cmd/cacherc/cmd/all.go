@@ -13,27 +13,65 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var (
+	allPageSize       int32
+	allBatchSize      int32
+	allFilter         string
+	allFilterLanguage string
+	allIncludeDeleted bool
+)
+
 // allCmd represents the all command.
 var allCmd = &cobra.Command{
 	Use:   "all",
 	Short: "Get all known hardware for facility",
 	Run: func(cmd *cobra.Command, args []string) {
 		conn := connectGRPC(cmd.Flags().GetString("facility"))
-		alls, err := conn.All(context.Background(), &cacher.Empty{})
-		if err != nil {
-			log.Fatal(err)
+		req := &cacher.AllRequest{
+			PageSize:       allPageSize,
+			BatchSize:      allBatchSize,
+			Filter:         allFilter,
+			FilterLanguage: allFilterLanguage,
+			FieldMask:      fieldMask(),
+			IncludeDeleted: allIncludeDeleted,
 		}
+		for {
+			alls, err := conn.All(context.Background(), req)
+			if err != nil {
+				log.Fatal(err)
+			}
 
-		var hw *cacher.Hardware
-		for hw, err = alls.Recv(); err == nil && hw != nil; hw, err = alls.Recv() {
-			fmt.Println(hw.JSON)
-		}
-		if err != nil && !errors.Is(err, io.EOF) {
-			log.Fatal(err)
+			req.PageToken = ""
+
+			var resp *cacher.AllResponse
+			for resp, err = alls.Recv(); err == nil && resp != nil; resp, err = alls.Recv() {
+				if resp.JSON != "" {
+					fmt.Println(resp.JSON)
+				}
+				for _, j := range resp.Batch {
+					fmt.Println(j)
+				}
+				if resp.NextPageToken != "" {
+					req.PageToken = resp.NextPageToken
+				}
+			}
+			if err != nil && !errors.Is(err, io.EOF) {
+				log.Fatal(err)
+			}
+
+			if req.PageToken == "" {
+				return
+			}
 		}
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(allCmd)
+	addFieldsFlag(allCmd)
+	allCmd.Flags().Int32Var(&allPageSize, "page-size", 0, "fetch hardware in pages of this many records (at most 10000), all at once if 0")
+	allCmd.Flags().Int32Var(&allBatchSize, "batch-size", 100, "records per message")
+	allCmd.Flags().StringVar(&allFilter, "filter", "", "only get hardware matching this expression")
+	allCmd.Flags().StringVar(&allFilterLanguage, "filter-language", "cel", "language of --filter, cel or jsonpath")
+	allCmd.Flags().BoolVar(&allIncludeDeleted, "include-deleted", false, "also get deleted hardware still kept as tombstones")
 }
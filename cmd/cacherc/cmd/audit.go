@@ -0,0 +1,66 @@
+// Copyright © 2026 packet.net
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/packethost/cacher/protos/cacher"
+	"github.com/spf13/cobra"
+)
+
+var (
+	auditSince time.Duration
+	auditLimit int32
+)
+
+// auditCmd represents the audit command.
+var auditCmd = &cobra.Command{
+	Use:     "audit [id]",
+	Short:   "Get the audit trail of hardware mutations",
+	Example: "cacherc audit --since 24h 224ee6ab-ad62-4070-a900-ed816444cec0",
+	Args: func(_ *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return nil
+		}
+		if len(args) > 1 {
+			return errors.New("accepts at most one id")
+		}
+
+		return verifyUUIDs(args)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		req := &cacher.AuditRequest{Limit: auditLimit}
+		if len(args) == 1 {
+			req.ID = args[0]
+		}
+		if auditSince > 0 {
+			req.Since = time.Now().Add(-auditSince).Unix()
+		}
+
+		conn := connectGRPC(cmd.Flags().GetString("facility"))
+		stream, err := conn.Audit(context.Background(), req)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		var e *cacher.AuditEntry
+		for e, err = stream.Recv(); err == nil && e != nil; e, err = stream.Recv() {
+			fmt.Println(e.JSON)
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+	auditCmd.Flags().DurationVar(&auditSince, "since", 0, "only show entries recorded within this duration")
+	auditCmd.Flags().Int32Var(&auditLimit, "limit", 0, "only show the most recent entries")
+}
@@ -0,0 +1,51 @@
+// Copyright © 2026 packet.net
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+
+	"github.com/packethost/cacher/protos/cacher"
+	"github.com/spf13/cobra"
+)
+
+// cidrCmd represents the cidr command.
+var cidrCmd = &cobra.Command{
+	Use:     "cidr",
+	Short:   "Get the ids of hardware with ips in any of the given prefixes",
+	Example: "cacherc cidr 10.1.4.0/23 2604:1380:1:2300::/56",
+	Args: func(_ *cobra.Command, args []string) error {
+		if len(args) < 1 {
+			return fmt.Errorf("requires at least one cidr")
+		}
+
+		for _, arg := range args {
+			if _, _, err := net.ParseCIDR(arg); err != nil {
+				return fmt.Errorf("invalid cidr: %s", arg)
+			}
+		}
+
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		conn := connectGRPC(cmd.Flags().GetString("facility"))
+		for _, cidr := range args {
+			resp, err := conn.ByCIDR(context.Background(), &cacher.CIDRRequest{CIDR: cidr})
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			for _, m := range resp.Matches {
+				fmt.Println(m.ID, strings.Join(m.IPs, " "))
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cidrCmd)
+}
@@ -0,0 +1,34 @@
+// Copyright © 2026 packet.net
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/packethost/cacher/protos/cacher"
+	"github.com/spf13/cobra"
+)
+
+// conflictsCmd represents the conflicts command.
+var conflictsCmd = &cobra.Command{
+	Use:   "conflicts",
+	Short: "List mac and ip addresses claimed by more than one hardware",
+	Run: func(cmd *cobra.Command, args []string) {
+		conn := connectGRPC(cmd.Flags().GetString("facility"))
+		resp, err := conn.Conflicts(context.Background(), &cacher.Empty{})
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		for _, c := range resp.Conflicts {
+			fmt.Println(c.Kind, c.Value, c.Owner, strings.Join(c.IDs, ","))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(conflictsCmd)
+}
@@ -0,0 +1,42 @@
+// Copyright © 2026 packet.net
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/packethost/cacher/protos/cacher"
+	"github.com/spf13/cobra"
+)
+
+var fsckRepair bool
+
+// fsckCmd represents the fsck command.
+var fsckCmd = &cobra.Command{
+	Use:   "fsck",
+	Short: "Check the indexes and lookups agree with the stored hardware",
+	Run: func(cmd *cobra.Command, args []string) {
+		conn := connectGRPC(cmd.Flags().GetString("facility"))
+		resp, err := conn.Fsck(context.Background(), &cacher.FsckRequest{Repair: fsckRepair})
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		for _, p := range resp.Problems {
+			fmt.Println(p.Index, p.Kind, p.Key, p.Got, p.Want)
+		}
+
+		repaired := ""
+		if resp.Repaired {
+			repaired = ", repaired"
+		}
+		fmt.Printf("checked %d hardware, found %d problems%s\n", resp.Hardware, len(resp.Problems), repaired)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(fsckCmd)
+	fsckCmd.Flags().BoolVar(&fsckRepair, "repair", false, "fix the problems found")
+}
@@ -0,0 +1,44 @@
+// Copyright © 2026 packet.net
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/packethost/cacher/protos/cacher"
+	"github.com/spf13/cobra"
+)
+
+// historyCmd represents the history command.
+var historyCmd = &cobra.Command{
+	Use:     "history",
+	Short:   "Get the kept revisions of hardware by id, oldest first",
+	Example: "cacherc history 224ee6ab-ad62-4070-a900-ed816444cec0",
+	Args: func(_ *cobra.Command, args []string) error {
+		return verifyUUIDs(args)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		conn := connectGRPC(cmd.Flags().GetString("facility"))
+		for _, id := range args {
+			resp, err := conn.History(context.Background(), &cacher.HistoryRequest{ID: id})
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			for _, r := range resp.Revisions {
+				state := ""
+				if r.Deleted {
+					state = " deleted"
+				}
+				fmt.Printf("%d %s %s%s %s\n", r.Revision, time.Unix(r.Time, 0).UTC().Format(time.RFC3339), r.Source, state, r.JSON)
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+}
@@ -6,6 +6,8 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
+	"time"
 
 	"github.com/packethost/cacher/protos/cacher"
 	"github.com/spf13/cobra"
@@ -21,11 +23,19 @@ var idCmd = &cobra.Command{
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 		conn := connectGRPC(cmd.Flags().GetString("facility"))
+		at, err := asOfTime()
+		if err != nil {
+			log.Fatal(err)
+		}
+
 		for _, id := range args {
-			hw, err := conn.ByID(context.Background(), &cacher.GetRequest{ID: id})
+			hw, err := conn.ByID(context.Background(), &cacher.GetRequest{ID: id, FieldMask: fieldMask(), AsOfTime: at})
 			if err != nil {
 				log.Fatal(err)
 			}
+			if hw.Deleted {
+				fmt.Fprintln(os.Stderr, id, "deleted at", time.Unix(hw.DeletedAt, 0).UTC().Format(time.RFC3339))
+			}
 			fmt.Println(hw.JSON)
 		}
 	},
@@ -33,4 +43,6 @@ var idCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(idCmd)
+	addFieldsFlag(idCmd)
+	addAsOfFlag(idCmd)
 }
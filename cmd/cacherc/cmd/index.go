@@ -0,0 +1,38 @@
+// Copyright © 2026 packet.net
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/packethost/cacher/protos/cacher"
+	"github.com/spf13/cobra"
+)
+
+// indexCmd represents the index command.
+var indexCmd = &cobra.Command{
+	Use:     "index name value...",
+	Short:   "Get hardware by a value of a secondary index",
+	Example: "cacherc index hostname sw-01.ewr1 sw-02.ewr1",
+	Args:    cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		conn := connectGRPC(cmd.Flags().GetString("facility"))
+		for _, value := range args[1:] {
+			resp, err := conn.ByIndex(context.Background(), &cacher.IndexRequest{Name: args[0], Value: value, FieldMask: fieldMask()})
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			for _, j := range resp.JSON {
+				fmt.Println(j)
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(indexCmd)
+	addFieldsFlag(indexCmd)
+}
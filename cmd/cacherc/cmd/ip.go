@@ -28,8 +28,13 @@ var ipCmd = &cobra.Command{
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 		conn := connectGRPC(cmd.Flags().GetString("facility"))
+		at, err := asOfTime()
+		if err != nil {
+			log.Fatal(err)
+		}
+
 		for _, ip := range args {
-			hw, err := conn.ByIP(context.Background(), &cacher.GetRequest{IP: ip})
+			hw, err := conn.ByIP(context.Background(), &cacher.GetRequest{IP: ip, FieldMask: fieldMask(), AsOfTime: at})
 			if err != nil {
 				log.Fatal(err)
 			}
@@ -40,4 +45,6 @@ var ipCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(ipCmd)
+	addFieldsFlag(ipCmd)
+	addAsOfFlag(ipCmd)
 }
@@ -28,8 +28,13 @@ var macCmd = &cobra.Command{
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 		conn := connectGRPC(cmd.Flags().GetString("facility"))
+		at, err := asOfTime()
+		if err != nil {
+			log.Fatal(err)
+		}
+
 		for _, mac := range args {
-			hw, err := conn.ByMAC(context.Background(), &cacher.GetRequest{MAC: mac})
+			hw, err := conn.ByMAC(context.Background(), &cacher.GetRequest{MAC: mac, FieldMask: fieldMask(), AsOfTime: at})
 			if err != nil {
 				log.Fatal(err)
 			}
@@ -40,4 +45,6 @@ var macCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(macCmd)
+	addFieldsFlag(macCmd)
+	addAsOfFlag(macCmd)
 }
@@ -0,0 +1,50 @@
+// Copyright © 2026 packet.net
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/packethost/cacher/protos/cacher"
+	"github.com/spf13/cobra"
+)
+
+var (
+	patchType     string
+	patchRevision uint64
+)
+
+// patchCmd represents the patch command.
+var patchCmd = &cobra.Command{
+	Use:     "patch",
+	Short:   "Patch hardware by id",
+	Example: `cacherc patch 224ee6ab-ad62-4070-a900-ed816444cec0 '{"allow_pxe":false}'`,
+	Args: func(_ *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			return fmt.Errorf("requires an id and a patch")
+		}
+
+		return verifyUUIDs(args[:1])
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		conn := connectGRPC(cmd.Flags().GetString("facility"))
+		hw, err := conn.Patch(context.Background(), &cacher.PatchRequest{
+			ID:               args[0],
+			Patch:            args[1],
+			PatchType:        patchType,
+			ExpectedRevision: patchRevision,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(hw.JSON)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(patchCmd)
+	patchCmd.Flags().StringVar(&patchType, "type", "merge", "merge for an RFC 7396 merge patch or json for an RFC 6902 JSON patch")
+	patchCmd.Flags().Uint64Var(&patchRevision, "revision", 0, "only patch if the hardware is at this revision, 0 to always patch")
+}
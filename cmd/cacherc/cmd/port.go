@@ -0,0 +1,35 @@
+// Copyright © 2026 packet.net
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/packethost/cacher/protos/cacher"
+	"github.com/spf13/cobra"
+)
+
+// portCmd represents the port command.
+var portCmd = &cobra.Command{
+	Use:     "port switch port...",
+	Short:   "Get the hardware connected to a switch port",
+	Example: "cacherc port leaf-3 xe-0/0/12 xe-0/0/13",
+	Args:    cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		conn := connectGRPC(cmd.Flags().GetString("facility"))
+		for _, port := range args[1:] {
+			resp, err := conn.ByPort(context.Background(), &cacher.PortRequest{Switch: args[0], Port: port, FieldMask: fieldMask()})
+			if err != nil {
+				log.Fatal(err)
+			}
+			fmt.Println(resp.JSON)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(portCmd)
+	addFieldsFlag(portCmd)
+}
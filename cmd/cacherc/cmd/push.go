@@ -3,22 +3,39 @@
 package cmd
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"os"
 	"strings"
 
 	"github.com/packethost/cacher/protos/cacher"
 	"github.com/spf13/cobra"
+	"google.golang.org/grpc/codes"
+)
+
+var (
+	pushRevision uint64
+	pushAtomic   bool
+	pushFile     string
 )
 
 // pushCmd represents the push command.
 var pushCmd = &cobra.Command{
-	Use:     "push",
-	Short:   "Push new hardware to cacher",
-	Example: `cacherc push '{"id":"2a1519e5-781c-4251-a979-3a6bedb8ba59", ...}' '{"id:"315169a4-a863-43ef-8817-2b6a57bd1eef", ...}'`,
+	Use:   "push",
+	Short: "Push new hardware to cacher",
+	Long:  "Push new hardware to cacher, given as arguments or as JSON lines read from stdin or --file.",
+	Example: `cacherc push '{"id":"2a1519e5-781c-4251-a979-3a6bedb8ba59", ...}' '{"id:"315169a4-a863-43ef-8817-2b6a57bd1eef", ...}'
+cacherc push --file hardware.jsonl`,
 	Args: func(_ *cobra.Command, args []string) error {
+		if len(args) > 0 && pushFile != "" {
+			return errors.New("push documents given as arguments or with --file, not both")
+		}
+
 		s := struct {
 			ID string
 		}{}
@@ -34,14 +51,137 @@ var pushCmd = &cobra.Command{
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 		conn := connectGRPC(cmd.Flags().GetString("facility"))
+		if len(args) == 0 {
+			r := os.Stdin
+			if pushFile != "" && pushFile != "-" {
+				f, err := os.Open(pushFile)
+				if err != nil {
+					log.Fatal(err)
+				}
+				defer f.Close()
+
+				r = f
+			}
+
+			if pushAtomic {
+				pushBatch(conn, readLines(r))
+			} else {
+				pushStream(conn, r)
+			}
+
+			return
+		}
+
+		if pushAtomic {
+			pushBatch(conn, args)
+
+			return
+		}
+
 		for _, j := range args {
-			if _, err := conn.Push(context.Background(), &cacher.PushRequest{Data: j}); err != nil {
+			if _, err := conn.Push(context.Background(), &cacher.PushRequest{Data: j, ExpectedRevision: pushRevision}); err != nil {
 				log.Fatal(err)
 			}
 		}
 	},
 }
 
+// pushBatch pushes all of js or none of them, reporting the documents that failed.
+func pushBatch(conn cacher.CacherClient, js []string) {
+	req := &cacher.PushBatchRequest{}
+	for _, j := range js {
+		req.Documents = append(req.Documents, &cacher.PushRequest{Data: j, ExpectedRevision: pushRevision})
+	}
+
+	resp, err := conn.PushBatch(context.Background(), req)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if resp.Committed {
+		return
+	}
+
+	for i, r := range resp.Results {
+		if r.Error != "" {
+			fmt.Fprintf(os.Stderr, "document %d: %s: %s\n", i, codes.Code(r.Code), r.Error)
+		}
+	}
+
+	log.Fatal("nothing was pushed")
+}
+
+// pushStream pushes the JSON lines of r in a single stream, reporting the documents that failed by line number.
+func pushStream(conn cacher.CacherClient, r io.Reader) {
+	stream, err := conn.PushStream(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	lines := []int{} // of each document sent
+	sc := newLineScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		j := strings.TrimSpace(sc.Text())
+		if j == "" {
+			continue
+		}
+
+		lines = append(lines, n)
+		if err := stream.Send(&cacher.PushRequest{Data: j, ExpectedRevision: pushRevision}); err != nil {
+			// the reason comes with CloseAndRecv
+			break
+		}
+	}
+
+	if err := sc.Err(); err != nil {
+		log.Fatal(err)
+	}
+
+	summary, err := stream.CloseAndRecv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, e := range summary.Errors {
+		fmt.Fprintf(os.Stderr, "line %d: %s: %s\n", lines[e.Index], codes.Code(e.Code), e.Error)
+	}
+
+	fmt.Fprintf(os.Stderr, "accepted %d, unchanged %d, rejected %d\n", summary.Accepted, summary.Unchanged, summary.Rejected)
+
+	if summary.Rejected > 0 {
+		os.Exit(1)
+	}
+}
+
+// readLines returns the non empty lines of r.
+func readLines(r io.Reader) []string {
+	var js []string
+
+	sc := newLineScanner(r)
+	for sc.Scan() {
+		if j := strings.TrimSpace(sc.Text()); j != "" {
+			js = append(js, j)
+		}
+	}
+
+	if err := sc.Err(); err != nil {
+		log.Fatal(err)
+	}
+
+	return js
+}
+
+// newLineScanner returns a scanner of the lines of r, allowing for large documents.
+func newLineScanner(r io.Reader) *bufio.Scanner {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16<<20)
+
+	return sc
+}
+
 func init() {
 	rootCmd.AddCommand(pushCmd)
+	pushCmd.Flags().Uint64Var(&pushRevision, "revision", 0, "only push if the hardware is at this revision, 0 to always push")
+	pushCmd.Flags().BoolVar(&pushAtomic, "atomic", false, "push all the documents or none of them")
+	pushCmd.Flags().StringVar(&pushFile, "file", "", "read JSON lines from this file instead of stdin, when not given any documents as arguments")
 }
@@ -5,15 +5,22 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/packethost/cacher/client"
 	"github.com/pkg/errors"
 	uuid "github.com/satori/go.uuid"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
-var cfgFile string
+var (
+	cfgFile string
+	fields  []string
+	asOf    string
+)
 
 // rootCmd represents the base command when called without any subcommands.
 var rootCmd = &cobra.Command{
@@ -34,6 +41,43 @@ func connectGRPC(facility string, err error) client.CacherClient {
 	return c
 }
 
+// addFieldsFlag adds the --fields flag to a command getting hardware.
+func addFieldsFlag(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&fields, "fields", nil, "only get these dotted JSON paths of the hardware, e.g. id,network_ports.data.mac")
+}
+
+// fieldMask returns the mask of the --fields flag, nil to get full documents.
+func fieldMask() *fieldmaskpb.FieldMask {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	return &fieldmaskpb.FieldMask{Paths: fields}
+}
+
+// addAsOfFlag adds the --as-of flag to a command looking hardware up.
+func addAsOfFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&asOf, "as-of", "", "get the hardware as it was at this RFC 3339 time, or this long ago, e.g. 1h")
+}
+
+// asOfTime returns the time of the --as-of flag, nil for now.
+func asOfTime() (*timestamppb.Timestamp, error) {
+	if asOf == "" {
+		return nil, nil
+	}
+
+	if d, err := time.ParseDuration(asOf); err == nil {
+		return timestamppb.New(time.Now().Add(-d)), nil
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, asOf)
+	if err != nil {
+		return nil, errors.Errorf("invalid --as-of %q, want an RFC 3339 time or a duration", asOf)
+	}
+
+	return timestamppb.New(t), nil
+}
+
 func verifyUUIDs(args []string) error {
 	if len(args) < 1 {
 		return errors.New("requires at least one id")
@@ -0,0 +1,36 @@
+// Copyright © 2026 packet.net
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/packethost/cacher/protos/cacher"
+	"github.com/spf13/cobra"
+)
+
+// statesCmd represents the states command.
+var statesCmd = &cobra.Command{
+	Use:   "states",
+	Short: "List the states pushed hardware can move to from each state",
+	Run: func(cmd *cobra.Command, args []string) {
+		conn := connectGRPC(cmd.Flags().GetString("facility"))
+		resp, err := conn.StateGraph(context.Background(), &cacher.Empty{})
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Println("policy", resp.Policy)
+
+		for _, s := range resp.States {
+			fmt.Println(s.State, strings.Join(s.Next, ","))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statesCmd)
+}
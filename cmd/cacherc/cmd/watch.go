@@ -27,7 +27,7 @@ var watchCmd = &cobra.Command{
 		stdoutLock := sync.Mutex{}
 		for _, id := range args {
 			go func(id string) {
-				stream, err := conn.Watch(context.Background(), &cacher.GetRequest{ID: id})
+				stream, err := conn.Watch(context.Background(), &cacher.GetRequest{ID: id, FieldMask: fieldMask()})
 				if err != nil {
 					log.Fatal(err)
 				}
@@ -49,5 +49,6 @@ var watchCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(watchCmd)
+	addFieldsFlag(watchCmd)
 	watchCmd.Flags().String("id", "", "id of the hardware")
 }
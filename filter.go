@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/PaesslerAG/gval"
+	"github.com/PaesslerAG/jsonpath"
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/interpreter"
+	"github.com/packethost/pkg/env"
+	"github.com/pkg/errors"
+)
+
+const (
+	filterCEL      = "cel"
+	filterJSONPath = "jsonpath"
+)
+
+// filterLimits bound the filter expressions clients may send.
+type filterLimits struct {
+	// maxLength is the longest accepted expression, in bytes
+	maxLength int
+	// cost is the max CEL runtime cost of evaluating an expression against a single record
+	cost uint64
+}
+
+func filterLimitsFromEnv() filterLimits {
+	return filterLimits{
+		maxLength: env.Int("CACHER_FILTER_MAX_LENGTH", 1024),
+		cost:      uint64(env.Int("CACHER_FILTER_COST_LIMIT", 100000)),
+	}
+}
+
+// invalidFilterError is returned for expressions that can not be used, as opposed to failures evaluating them.
+type invalidFilterError string
+
+func (e invalidFilterError) Error() string {
+	return "invalid filter: " + string(e)
+}
+
+// filter reports whether a hardware record matches an expression.
+// A record the expression can not be evaluated against, e.g. because it lacks a field, does not match.
+type filter func(j string) (bool, error)
+
+// celEnv declares the hardware record as hw.
+var celEnv, _ = cel.NewEnv(cel.Variable("hw", cel.DynType))
+
+// newFilter compiles expr written in language, which is CEL if empty, or returns nil if expr is empty.
+func newFilter(language, expr string, limits filterLimits) (filter, error) {
+	if expr == "" {
+		return nil, nil
+	}
+
+	if limits.maxLength > 0 && len(expr) > limits.maxLength {
+		return nil, invalidFilterError(fmt.Sprintf("longer than %d bytes", limits.maxLength))
+	}
+
+	switch language {
+	case "", filterCEL:
+		return newCELFilter(expr, limits.cost)
+	case filterJSONPath:
+		return newJSONPathFilter(expr)
+	}
+
+	return nil, invalidFilterError(fmt.Sprintf("unknown language %q", language))
+}
+
+// newCELFilter compiles a CEL expression that must evaluate to a bool, e.g. `hw.state == "active"`.
+// Evaluating it against a record may cost at most cost, 0 means unlimited.
+func newCELFilter(expr string, cost uint64) (filter, error) {
+	ast, iss := celEnv.Compile(expr)
+	if iss.Err() != nil {
+		return nil, invalidFilterError(iss.Err().Error())
+	}
+
+	if t := ast.OutputType(); t != cel.BoolType && t != cel.DynType {
+		return nil, invalidFilterError(fmt.Sprintf("expression is of type %s, not bool", t))
+	}
+
+	var opts []cel.ProgramOption
+	if cost > 0 {
+		opts = append(opts, cel.CostLimit(cost))
+	}
+
+	prg, err := celEnv.Program(ast, opts...)
+	if err != nil {
+		return nil, invalidFilterError(err.Error())
+	}
+
+	return func(j string) (bool, error) {
+		var hw interface{}
+		if err := json.Unmarshal([]byte(j), &hw); err != nil {
+			return false, errors.Wrap(err, "decode json")
+		}
+
+		v, _, err := prg.Eval(map[string]interface{}{"hw": hw})
+		if err != nil {
+			var cancelled interpreter.EvalCancelledError
+			if errors.As(err, &cancelled) {
+				return false, invalidFilterError(cancelled.Message)
+			}
+
+			return false, nil
+		}
+
+		return v == types.True, nil
+	}, nil
+}
+
+// newJSONPathFilter compiles a JSONPath expression, e.g. `$.network_ports[?(@.data.mac == "00:00:00:00:00:01")]`.
+// A record matches if the expression evaluates to true or selects anything.
+// JSONPath has no loops or functions so evaluating it is bounded by the size of the record and expression.
+func newJSONPathFilter(expr string) (filter, error) {
+	eval, err := gval.Full(jsonpath.Language()).NewEvaluable(expr)
+	if err != nil {
+		return nil, invalidFilterError(err.Error())
+	}
+
+	return func(j string) (bool, error) {
+		var hw interface{}
+		if err := json.Unmarshal([]byte(j), &hw); err != nil {
+			return false, errors.Wrap(err, "decode json")
+		}
+
+		v, err := eval(context.Background(), hw)
+		if err != nil {
+			return false, nil
+		}
+
+		switch v := v.(type) {
+		case nil:
+			return false, nil
+		case bool:
+			return v, nil
+		}
+
+		rv := reflect.ValueOf(v)
+		switch rv.Kind() {
+		case reflect.Slice, reflect.Map, reflect.String:
+			return rv.Len() > 0, nil
+		}
+
+		return true, nil
+	}, nil
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/packethost/cacher/hardware"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilter(t *testing.T) {
+	assert := require.New(t)
+
+	active := `{"id":"1","state":"active","facility_code":"ewr1","network_ports":[{"name":"eth0","data":{"mac":"00:00:00:00:00:01"}}]}`
+	provisioning := `{"id":"2","state":"provisioning","facility_code":"ewr1","network_ports":[]}`
+
+	limits := filterLimits{maxLength: 1024, cost: 1000}
+
+	for _, test := range []struct {
+		language string
+		expr     string
+		matches  []bool
+	}{
+		{language: "", expr: `hw.state == "active"`, matches: []bool{true, false}},
+		{language: filterCEL, expr: `hw.network_ports.exists(p, p.data.mac == "00:00:00:00:00:01")`, matches: []bool{true, false}},
+		{language: filterCEL, expr: `hw.plan == "c3.small"`, matches: []bool{false, false}},
+		{language: filterCEL, expr: `has(hw.plan) || hw.facility_code == "ewr1"`, matches: []bool{true, true}},
+		{language: filterJSONPath, expr: `$.state == "provisioning"`, matches: []bool{false, true}},
+		{language: filterJSONPath, expr: `$.network_ports[?(@.data.mac == "00:00:00:00:00:01")]`, matches: []bool{true, false}},
+		{language: filterJSONPath, expr: `$.network_ports[*]`, matches: []bool{true, false}},
+	} {
+		f, err := newFilter(test.language, test.expr, limits)
+		assert.NoError(err, test.expr)
+		assert.NotNil(f, test.expr)
+
+		for i, j := range []string{active, provisioning} {
+			ok, err := f(j)
+			assert.NoError(err, test.expr)
+			assert.Equal(test.matches[i], ok, "%s on %s", test.expr, j)
+		}
+	}
+
+	f, err := newFilter(filterCEL, "", limits)
+	assert.NoError(err)
+	assert.Nil(f)
+
+	for _, test := range []struct {
+		language string
+		expr     string
+	}{
+		{language: filterCEL, expr: `hw.state ==`},
+		{language: filterCEL, expr: `hw.id + 1`},
+		{language: filterCEL, expr: `"active"`},
+		{language: filterJSONPath, expr: `$.network_ports[?(`},
+		{language: "jq", expr: `.state`},
+		{language: filterCEL, expr: `hw.state == "` + string(make([]byte, 1024)) + `"`},
+	} {
+		_, err := newFilter(test.language, test.expr, limits)
+		assert.ErrorAs(err, new(invalidFilterError), test.expr)
+	}
+
+	// expensive expressions are stopped once they exceed the cost limit
+	f, err = newFilter(filterCEL, `[1,2,3,4,5,6,7,8,9,10].all(a, [1,2,3,4,5,6,7,8,9,10].all(b, [1,2,3,4,5,6,7,8,9,10].all(c, a+b+c > 0)))`, limits)
+	assert.NoError(err)
+
+	_, err = f(active)
+	assert.ErrorAs(err, new(invalidFilterError))
+}
+
+func TestListHandler(t *testing.T) {
+	assert := require.New(t)
+
+	s := &server{store: hardware.New(), ingestDone: true, filterLimits: filterLimits{maxLength: 1024, cost: 1000}}
+	for i := 0; i < 6; i++ {
+		state := "active"
+		if i%2 == 1 {
+			state = "provisioning"
+		}
+
+		_, err := s.store.Add(fmt.Sprintf(`{"id":"%08d-0000-0000-0000-000000000000","state":%q}`, i, state))
+		assert.NoError(err)
+	}
+
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.listHandler(w, httptest.NewRequest(http.MethodGet, "/hardware?"+query, nil))
+
+		return w
+	}
+
+	var got []string
+
+	q := url.Values{"filter": {`hw.state == "active"`}, "page_size": {"4"}}
+	w := get(q.Encode())
+	assert.Equal(http.StatusOK, w.Code)
+	got = append(got, strings.Fields(w.Body.String())...)
+
+	next := w.Header().Get("X-Next-Page-Token")
+	assert.NotEmpty(next)
+
+	q.Set("page_token", next)
+	w = get(q.Encode())
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Empty(w.Header().Get("X-Next-Page-Token"))
+	got = append(got, strings.Fields(w.Body.String())...)
+
+	assert.Len(got, 3)
+	for i, j := range got {
+		assert.Contains(j, fmt.Sprintf("%08d-", i*2))
+	}
+
+	assert.Equal(http.StatusBadRequest, get(url.Values{"filter": {"hw.state =="}}.Encode()).Code)
+	assert.Equal(http.StatusBadRequest, get(url.Values{"page_token": {"!"}}.Encode()).Code)
+	assert.Equal(http.StatusBadRequest, get(url.Values{"page_size": {"x"}}.Encode()).Code)
+	assert.Equal(http.StatusBadRequest, get(url.Values{"page_size": {"-1"}}.Encode()).Code)
+	assert.Equal(http.StatusBadRequest, get(url.Values{"page_size": {strconv.Itoa(maxPageSize + 1)}}.Encode()).Code)
+}
+
+func TestListHandlerPageSize(t *testing.T) {
+	assert := require.New(t)
+
+	s := &server{store: hardware.New(), ingestDone: true}
+	for i := 0; i <= listHandlerPageSize; i++ {
+		_, err := s.store.Add(fmt.Sprintf(`{"id":"%08d-0000-0000-0000-000000000000"}`, i))
+		assert.NoError(err)
+	}
+
+	// a page is buffered, so there is always a limit to it
+	w := httptest.NewRecorder()
+	s.listHandler(w, httptest.NewRequest(http.MethodGet, "/hardware", nil))
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Len(strings.Fields(w.Body.String()), listHandlerPageSize)
+	assert.NotEmpty(w.Header().Get("X-Next-Page-Token"))
+}
+
+func TestHardwareHandler(t *testing.T) {
+	assert := require.New(t)
+
+	s := &server{store: hardware.New(), ingestDone: true, filterLimits: filterLimits{maxLength: 1024, cost: 1000}}
+
+	var a *authz
+	assert.NotNil(s.hardwareHandler(a, nil))
+
+	// requireScope can't authenticate anything without tokens
+	assert.Nil(s.hardwareHandler(&authz{mtls: true}, nil))
+
+	f := filepath.Join(t.TempDir(), "tokens.json")
+	writeTokens(t, f, `{"tokens":[{"name":"boots","token":"t1","scopes":["read"]}]}`)
+
+	tokens, err := newTokenStore(f)
+	assert.NoError(err)
+
+	h := s.hardwareHandler(&authz{mtls: true, tokens: tokens}, nil)
+	assert.NotNil(h)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/hardware", http.NoBody))
+	assert.Equal(http.StatusUnauthorized, w.Code)
+}
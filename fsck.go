@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/packethost/cacher/hardware"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// The indexes and kinds of problems fsck reports in the metrics, every combination of which is always set.
+var (
+	fsckIndexes = []string{
+		hardware.IndexIP, hardware.IndexMAC, hardware.IndexPort, hardware.IndexIDs, hardware.IndexCIDR,
+		hardware.IndexSecondary, hardware.IndexClaims,
+		hardware.IndexSnapshotHW, hardware.IndexSnapshotIP, hardware.IndexSnapshotMAC, hardware.IndexSnapshotPort,
+	}
+	fsckKinds = []string{hardware.Dangling, hardware.Missing, hardware.Mismatched}
+)
+
+// fsck checks, and repairs if asked to, the indexes of hw, reporting what it found in the logs and metrics.
+func fsck(hw *hardware.Hardware, repair bool) hardware.FsckReport {
+	r := hw.Fsck(repair)
+
+	counts := map[[2]string]int{}
+
+	for _, p := range r.Problems {
+		counts[[2]string{p.Index, p.Kind}]++
+		logger.With("index", p.Index, "kind", p.Kind, "key", p.Key, "got", p.Got, "want", p.Want, "repaired", r.Repaired).
+			Info("index problem")
+	}
+
+	// the problems no longer found go back to 0
+	for _, index := range fsckIndexes {
+		for _, kind := range fsckKinds {
+			fsckProblems.With(prometheus.Labels{"index": index, "kind": kind}).Set(float64(counts[[2]string{index, kind}]))
+		}
+	}
+
+	if r.Repaired {
+		fsckRepairs.Add(float64(len(r.Problems)))
+	}
+
+	return r
+}
+
+// checkIndexes runs fsck on hw every interval until ctx is done.
+func checkIndexes(ctx context.Context, hw *hardware.Hardware, interval time.Duration, repair bool) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if r := fsck(hw, repair); len(r.Problems) > 0 {
+				logger.With("count", len(r.Problems), "repaired", r.Repaired).Info("found index problems")
+			}
+		}
+	}
+}
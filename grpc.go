@@ -2,6 +2,11 @@ package main
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -12,7 +17,10 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding/gzip"
 	"google.golang.org/grpc/status"
 )
 
@@ -23,13 +31,18 @@ type server struct {
 	packet *packngo.Client
 	quit   <-chan struct{}
 
-	hw *hardware.Hardware
+	store    hardware.Store
+	dictSize int // of the compression dictionary retrained after ingesting, 0 if not compressing
 
 	ingestReadyLock sync.RWMutex
 	ingestDone      bool
 
-	watchLock sync.RWMutex
-	watch     map[string]chan string
+	watchLock sync.Mutex
+	watch     map[string]chan struct{} // closed to evict the watcher of each id
+
+	auditLog *auditLog
+
+	filterLimits filterLimits
 }
 
 //go:generate protoc -I protos/cacher protos/cacher/cacher.proto --go_opt=paths=source_relative --go_out=plugins=grpc:protos/cacher
@@ -37,9 +50,9 @@ type server struct {
 //go:generate goimports -w protos/cacher/cacher.pb.go
 
 // Push implements cacher.CacherServer.
-func (s *server) Push(ctx context.Context, in *cacher.PushRequest) (*cacher.Empty, error) {
+func (s *server) Push(ctx context.Context, in *cacher.PushRequest) (*cacher.PushResponse, error) {
 	trace.SpanFromContext(ctx).AddEvent("push")
-	logger.Info("push")
+	logger.With("caller", callerName(ctx)).Info("push")
 	labels := prometheus.Labels{"method": "Push", "op": ""}
 	cacheInFlight.With(labels).Inc()
 	defer cacheInFlight.With(labels).Dec()
@@ -47,28 +60,240 @@ func (s *server) Push(ctx context.Context, in *cacher.PushRequest) (*cacher.Empt
 	cacheTotals.With(labels).Inc()
 	timer := prometheus.NewTimer(cacheDuration.With(labels))
 
-	id, err := s.hw.Add(in.Data)
+	w, err := s.push(in.Data, in.ExpectedRevision)
 	if err != nil {
 		cacheErrors.With(labels).Inc()
 		logger.Error(err)
 
+		return nil, writeError(err)
+	}
+
+	timer.ObserveDuration()
+
+	s.written(ctx, "Push", w, in.Data)
+
+	return &cacher.PushResponse{ID: w.ID, Revision: w.Revision, Unchanged: w.Unchanged}, nil
+}
+
+// Patch implements cacher.CacherServer.
+func (s *server) Patch(ctx context.Context, in *cacher.PatchRequest) (*cacher.Hardware, error) {
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("ID", in.ID))
+	logger.With("caller", callerName(ctx), "id", in.ID).Info("patch")
+	labels := prometheus.Labels{"method": "Patch", "op": ""}
+	cacheInFlight.With(labels).Inc()
+	defer cacheInFlight.With(labels).Dec()
+
+	cacheTotals.With(labels).Inc()
+	timer := prometheus.NewTimer(cacheDuration.With(labels))
+
+	hw, err := s.memory("Patch")
+	if err != nil {
+		cacheErrors.With(labels).Inc()
+
 		return nil, err
 	}
 
+	j, w, err := hw.Patch(in.ID, in.PatchType, in.Patch, hardware.FromPush, in.ExpectedRevision)
+	if err != nil {
+		cacheErrors.With(labels).Inc()
+		logger.Error(err)
+
+		return nil, writeError(err)
+	}
+
 	timer.ObserveDuration()
 
-	s.watchLock.RLock()
-	if ch := s.watch[id]; ch != nil {
-		select {
-		case ch <- in.Data:
-		default:
-			watchMissTotal.Inc()
-			logger.With("id", id).Info("skipping blocked watcher")
+	s.written(ctx, "Patch", w, j)
+
+	return &cacher.Hardware{JSON: j, Revision: w.Revision, Unchanged: w.Unchanged}, nil
+}
+
+// PushBatch implements cacher.CacherServer.
+func (s *server) PushBatch(ctx context.Context, in *cacher.PushBatchRequest) (*cacher.PushBatchResponse, error) {
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Int("documents", len(in.Documents)))
+	logger.With("caller", callerName(ctx), "documents", len(in.Documents)).Info("push batch")
+	labels := prometheus.Labels{"method": "PushBatch", "op": ""}
+	cacheInFlight.With(labels).Inc()
+	defer cacheInFlight.With(labels).Dec()
+
+	cacheTotals.With(labels).Inc()
+	timer := prometheus.NewTimer(cacheDuration.With(labels))
+
+	writes := make([]hardware.Write, len(in.Documents))
+	for i, d := range in.Documents {
+		writes[i] = hardware.Write{JSON: d.Data, Revision: d.ExpectedRevision}
+	}
+
+	hw, err := s.memory("PushBatch")
+	if err != nil {
+		cacheErrors.With(labels).Inc()
+
+		return nil, err
+	}
+
+	resp := &cacher.PushBatchResponse{Results: make([]*cacher.PushResult, len(writes))}
+
+	ws, err := hw.Batch(writes, hardware.FromPush)
+	if err != nil {
+		cacheErrors.With(labels).Inc()
+		logger.Error(err)
+
+		var errs hardware.BatchError
+		if !errors.As(err, &errs) {
+			return nil, err
+		}
+
+		for i, err := range errs {
+			resp.Results[i] = &cacher.PushResult{}
+			if err != nil {
+				st := status.Convert(writeError(err))
+				resp.Results[i].Error = st.Message()
+				resp.Results[i].Code = int32(st.Code())
+			}
+		}
+
+		return resp, nil
+	}
+
+	timer.ObserveDuration()
+
+	for i, w := range ws {
+		s.written(ctx, "PushBatch", w, writes[i].JSON)
+
+		resp.Results[i] = &cacher.PushResult{ID: w.ID, Revision: w.Revision, Unchanged: w.Unchanged}
+	}
+
+	resp.Committed = true
+
+	return resp, nil
+}
+
+// PushStream implements cacher.CacherServer.
+// Documents are stored one at a time as they are received, so gRPC's flow control holds back senders outpacing it.
+func (s *server) PushStream(stream cacher.Cacher_PushStreamServer) error {
+	ctx := stream.Context()
+	logger.With("caller", callerName(ctx)).Info("push stream")
+	labels := prometheus.Labels{"method": "PushStream", "op": ""}
+	cacheInFlight.With(labels).Inc()
+	defer cacheInFlight.With(labels).Dec()
+
+	cacheTotals.With(labels).Inc()
+	timer := prometheus.NewTimer(cacheDuration.With(labels))
+	defer timer.ObserveDuration()
+
+	summary := &cacher.PushSummary{}
+
+	for i := int64(0); ; i++ {
+		in, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			logger.With("accepted", summary.Accepted, "rejected", summary.Rejected, "unchanged", summary.Unchanged).Info("push stream done")
+
+			return stream.SendAndClose(summary)
+		}
+
+		if err != nil {
+			cacheErrors.With(labels).Inc()
+
+			return err
+		}
+
+		w, err := s.push(in.Data, in.ExpectedRevision)
+		if err != nil {
+			logger.With("index", i).Error(err)
+
+			st := status.Convert(writeError(err))
+			summary.Rejected++
+			summary.Errors = append(summary.Errors, &cacher.PushError{Index: i, Error: st.Message(), Code: int32(st.Code())})
+
+			continue
 		}
+
+		if w.Unchanged {
+			summary.Unchanged++
+		} else {
+			summary.Accepted++
+		}
+
+		s.written(ctx, "PushStream", w, in.Data)
 	}
-	s.watchLock.RUnlock()
+}
 
-	return &cacher.Empty{}, err
+// push stores j as pushed, checking its revision unless rev is 0.
+// Only the in memory store keeps revisions, so other stores fail with Unimplemented if rev is set.
+func (s *server) push(j string, rev uint64) (hardware.Written, error) {
+	if hw, ok := s.store.(*hardware.Hardware); ok {
+		return hw.CompareAndSwap(j, hardware.FromPush, rev)
+	}
+
+	if rev != 0 {
+		return hardware.Written{}, status.Errorf(codes.Unimplemented, "expected_revision is not supported by the %s store", storeName(s.store))
+	}
+
+	id, err := s.store.Add(j)
+
+	return hardware.Written{ID: id}, err
+}
+
+// memory returns the store if it is the in memory one, the only one supporting method, otherwise an Unimplemented
+// error.
+func (s *server) memory(method string) (*hardware.Hardware, error) {
+	if hw, ok := s.store.(*hardware.Hardware); ok {
+		return hw, nil
+	}
+
+	return nil, status.Errorf(codes.Unimplemented, "%s is not supported by the %s store", method, storeName(s.store))
+}
+
+// written audits hardware stored by method as j, unless it was unchanged.
+// Watchers are notified by the store.
+func (s *server) written(ctx context.Context, method string, w hardware.Written, j string) {
+	if w.Unchanged {
+		pushUnchanged.With(prometheus.Labels{"method": method}).Inc()
+
+		return
+	}
+
+	s.audit(ctx, method, w, j)
+}
+
+// writeError maps the errors of writing hardware to status codes.
+func writeError(err error) error {
+	switch {
+	case errors.Is(err, hardware.ErrConflict):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, hardware.ErrRevisionMismatch), errors.Is(err, hardware.ErrIllegalTransition):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, hardware.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, hardware.ErrInvalidPatch), errors.Is(err, hardware.ErrInvalidDocument),
+		errors.Is(err, hardware.ErrDuplicate):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, hardware.ErrSchema):
+		return schemaError(err)
+	}
+
+	return err
+}
+
+// schemaError reports a document violating the schema as InvalidArgument, with a BadRequest detail listing the JSON
+// pointer of each violation as its field.
+func schemaError(err error) error {
+	var serr *hardware.SchemaError
+	if !errors.As(err, &serr) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	br := &errdetails.BadRequest{}
+	for _, v := range serr.Violations {
+		br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{Field: v.Pointer, Description: v.Message})
+	}
+
+	st, derr := status.New(codes.InvalidArgument, err.Error()).WithDetails(br)
+	if derr != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return st.Err()
 }
 
 // Ingest implements cacher.CacherServer.
@@ -84,7 +309,7 @@ func (s *server) Ingest(ctx context.Context, _ *cacher.Empty) (*cacher.Empty, er
 	return &cacher.Empty{}, nil
 }
 
-func (s *server) by(method string, fn func() (string, error)) (*cacher.Hardware, error) {
+func (s *server) by(method string, in *cacher.GetRequest, fn func() (string, error)) (*cacher.Hardware, error) {
 	labels := prometheus.Labels{"method": method, "op": "get"}
 
 	cacheTotals.With(labels).Inc()
@@ -92,6 +317,12 @@ func (s *server) by(method string, fn func() (string, error)) (*cacher.Hardware,
 
 	defer cacheInFlight.With(labels).Dec()
 
+	mask, err := newFieldMask(in.FieldMask)
+	if err != nil {
+		cacheErrors.With(labels).Inc()
+		return &cacher.Hardware{}, err
+	}
+
 	timer := prometheus.NewTimer(cacheDuration.With(labels))
 	defer timer.ObserveDuration()
 
@@ -111,36 +342,418 @@ func (s *server) by(method string, fn func() (string, error)) (*cacher.Hardware,
 		}
 	}
 
+	j, err = mask.apply(j)
+	if err != nil {
+		cacheErrors.With(labels).Inc()
+		return &cacher.Hardware{}, err
+	}
+
 	cacheHits.With(labels).Inc()
 	return &cacher.Hardware{JSON: j}, nil
 }
 
+// asOfTime returns the time in looks hardware up at, as_of_time taking precedence over as_of, the zero time for now.
+func asOfTime(in *cacher.GetRequest) (time.Time, error) {
+	if in.AsOfTime != nil {
+		if err := in.AsOfTime.CheckValid(); err != nil {
+			return time.Time{}, status.Error(codes.InvalidArgument, "invalid as_of_time")
+		}
+
+		return in.AsOfTime.AsTime(), nil
+	}
+
+	if in.AsOf != 0 {
+		return time.Unix(in.AsOf, 0), nil
+	}
+
+	return time.Time{}, nil
+}
+
 // ByMAC implements cacher.CacherServer.
 func (s *server) ByMAC(ctx context.Context, in *cacher.GetRequest) (*cacher.Hardware, error) {
 	trace.SpanFromContext(ctx).SetAttributes(attribute.String("MAC", in.MAC))
-	return s.by("ByMAC", func() (string, error) {
-		return s.hw.ByMAC(in.MAC)
+	var (
+		port string
+		rev  uint64
+	)
+
+	mem, ok := s.store.(*hardware.Hardware)
+
+	var at time.Time
+
+	hw, err := s.by("ByMAC", in, func() (string, error) {
+		var err error
+		if at, err = asOfTime(in); err != nil {
+			return "", err
+		}
+
+		if !ok {
+			if !at.IsZero() {
+				return "", status.Errorf(codes.Unimplemented, "as_of is not supported by the %s store", storeName(s.store))
+			}
+
+			return s.store.ByMAC(in.MAC)
+		}
+
+		if !at.IsZero() {
+			return mem.ByMACAsOf(in.MAC, at)
+		}
+
+		j, r, p, err := mem.ByMACPort(in.MAC)
+		rev, port = r, p
+
+		return j, err
 	})
+	if err == nil && hw.JSON != "" && at.IsZero() && ok {
+		hw.Revision = rev
+		hw.Match = &cacher.Match{Port: port}
+	}
+
+	return hw, err
 }
 
 // ByIP implements cacher.CacherServer.
 func (s *server) ByIP(ctx context.Context, in *cacher.GetRequest) (*cacher.Hardware, error) {
 	trace.SpanFromContext(ctx).SetAttributes(attribute.String("IP", in.IP))
-	return s.by("ByIP", func() (string, error) {
-		return s.hw.ByIP(in.IP)
+	var (
+		m   hardware.IPMatch
+		rev uint64
+	)
+
+	mem, ok := s.store.(*hardware.Hardware)
+
+	var at time.Time
+
+	hw, err := s.by("ByIP", in, func() (string, error) {
+		var err error
+		if at, err = asOfTime(in); err != nil {
+			return "", err
+		}
+
+		if !ok {
+			if !at.IsZero() {
+				return "", status.Errorf(codes.Unimplemented, "as_of is not supported by the %s store", storeName(s.store))
+			}
+
+			return s.store.ByIP(in.IP)
+		}
+
+		if !at.IsZero() {
+			return mem.ByIPAsOf(in.IP, at)
+		}
+
+		j, r, match, err := mem.ByIPMatch(in.IP)
+		rev, m = r, match
+
+		return j, err
 	})
+	if err == nil && hw.JSON != "" && at.IsZero() && ok {
+		hw.Revision = rev
+		hw.Match = &cacher.Match{
+			AddressFamily: int32(m.Family),
+			Management:    m.Management,
+			Public:        m.Public,
+			Source:        m.Source,
+		}
+	}
+
+	return hw, err
 }
 
 // ByID implements cacher.CacherServer.
 func (s *server) ByID(ctx context.Context, in *cacher.GetRequest) (*cacher.Hardware, error) {
 	trace.SpanFromContext(ctx).SetAttributes(attribute.String("ID", in.ID))
-	return s.by("ByID", func() (string, error) {
-		return s.hw.ByID(in.ID)
+	var (
+		deleted time.Time
+		rev     uint64
+	)
+
+	mem, ok := s.store.(*hardware.Hardware)
+
+	var at time.Time
+
+	hw, err := s.by("ByID", in, func() (string, error) {
+		var err error
+		if at, err = asOfTime(in); err != nil {
+			return "", err
+		}
+
+		if !ok {
+			if !at.IsZero() {
+				return "", status.Errorf(codes.Unimplemented, "as_of is not supported by the %s store", storeName(s.store))
+			}
+
+			return s.store.Get(in.ID)
+		}
+
+		if !at.IsZero() {
+			return mem.ByIDAsOf(in.ID, at)
+		}
+
+		j, r, d, err := mem.ByIDStatus(in.ID)
+		rev, deleted = r, d
+
+		return j, err
 	})
+	if err == nil && hw.JSON != "" {
+		hw.Revision = rev
+	}
+
+	if err == nil && hw.JSON != "" && !deleted.IsZero() {
+		hw.Deleted = true
+		hw.DeletedAt = deleted.Unix()
+	}
+
+	return hw, err
+}
+
+// ByCIDR implements cacher.CacherServer.
+func (s *server) ByCIDR(ctx context.Context, in *cacher.CIDRRequest) (*cacher.CIDRResponse, error) {
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("CIDR", in.CIDR))
+	labels := prometheus.Labels{"method": "ByCIDR", "op": "get"}
+
+	cacheTotals.With(labels).Inc()
+	cacheInFlight.With(labels).Inc()
+	defer cacheInFlight.With(labels).Dec()
+
+	s.ingestReadyLock.RLock()
+	ready := s.ingestDone
+	s.ingestReadyLock.RUnlock()
+	if !ready {
+		cacheStalls.With(labels).Inc()
+		return &cacher.CIDRResponse{}, errors.New("DB is not ready")
+	}
+
+	hw, err := s.memory("ByCIDR")
+	if err != nil {
+		cacheErrors.With(labels).Inc()
+		return &cacher.CIDRResponse{}, err
+	}
+
+	timer := prometheus.NewTimer(cacheDuration.With(labels))
+	defer timer.ObserveDuration()
+
+	ms, err := hw.ByCIDR(in.CIDR)
+	if err != nil {
+		cacheErrors.With(labels).Inc()
+		return &cacher.CIDRResponse{}, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	resp := &cacher.CIDRResponse{Matches: make([]*cacher.CIDRMatch, 0, len(ms))}
+	for _, m := range ms {
+		resp.Matches = append(resp.Matches, &cacher.CIDRMatch{ID: m.ID, IPs: m.IPs})
+	}
+
+	cacheHits.With(labels).Inc()
+
+	return resp, nil
+}
+
+// ByIndex implements cacher.CacherServer.
+func (s *server) ByIndex(ctx context.Context, in *cacher.IndexRequest) (*cacher.IndexResponse, error) {
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("index", in.Name), attribute.String("value", in.Value))
+	labels := prometheus.Labels{"method": "ByIndex", "op": "get"}
+
+	cacheTotals.With(labels).Inc()
+	cacheInFlight.With(labels).Inc()
+	defer cacheInFlight.With(labels).Dec()
+
+	s.ingestReadyLock.RLock()
+	ready := s.ingestDone
+	s.ingestReadyLock.RUnlock()
+	if !ready {
+		cacheStalls.With(labels).Inc()
+		return &cacher.IndexResponse{}, errors.New("DB is not ready")
+	}
+
+	timer := prometheus.NewTimer(cacheDuration.With(labels))
+	defer timer.ObserveDuration()
+
+	mask, err := newFieldMask(in.FieldMask)
+	if err != nil {
+		cacheErrors.With(labels).Inc()
+		return &cacher.IndexResponse{}, err
+	}
+
+	hw, err := s.memory("ByIndex")
+	if err != nil {
+		cacheErrors.With(labels).Inc()
+		return &cacher.IndexResponse{}, err
+	}
+
+	js, err := hw.ByIndex(in.Name, in.Value)
+	if err != nil {
+		cacheErrors.With(labels).Inc()
+		if errors.Is(err, hardware.ErrUnknownIndex) {
+			return &cacher.IndexResponse{}, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		return &cacher.IndexResponse{}, err
+	}
+
+	for i, j := range js {
+		js[i], err = mask.apply(j)
+		if err != nil {
+			cacheErrors.With(labels).Inc()
+			return &cacher.IndexResponse{}, err
+		}
+	}
+
+	cacheHits.With(labels).Inc()
+
+	return &cacher.IndexResponse{JSON: js}, nil
+}
+
+// ByPort implements cacher.CacherServer.
+func (s *server) ByPort(ctx context.Context, in *cacher.PortRequest) (*cacher.PortResponse, error) {
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("switch", in.Switch), attribute.String("port", in.Port))
+
+	var port string
+
+	hw, err := s.by("ByPort", &cacher.GetRequest{FieldMask: in.FieldMask}, func() (string, error) {
+		hw, err := s.memory("ByPort")
+		if err != nil {
+			return "", err
+		}
+
+		j, p, err := hw.ByPort(in.Switch, in.Port)
+		port = p
+
+		return j, err
+	})
+	if err != nil {
+		return &cacher.PortResponse{}, err
+	}
+
+	return &cacher.PortResponse{JSON: hw.JSON, Port: port}, nil
+}
+
+// Conflicts implements cacher.CacherServer.
+func (s *server) Conflicts(ctx context.Context, _ *cacher.Empty) (*cacher.ConflictsResponse, error) {
+	labels := prometheus.Labels{"method": "Conflicts", "op": "get"}
+
+	cacheTotals.With(labels).Inc()
+	cacheInFlight.With(labels).Inc()
+	defer cacheInFlight.With(labels).Dec()
+
+	hw, err := s.memory("Conflicts")
+	if err != nil {
+		cacheErrors.With(labels).Inc()
+		return nil, err
+	}
+
+	cs := hw.Conflicts()
+
+	resp := &cacher.ConflictsResponse{Conflicts: make([]*cacher.Conflict, 0, len(cs))}
+	for _, c := range cs {
+		resp.Conflicts = append(resp.Conflicts, &cacher.Conflict{Kind: c.Kind, Value: c.Value, Owner: c.Owner, IDs: c.IDs})
+	}
+
+	cacheHits.With(labels).Inc()
+
+	return resp, nil
+}
+
+// StateGraph implements cacher.CacherServer.
+func (s *server) StateGraph(ctx context.Context, _ *cacher.Empty) (*cacher.StateGraphResponse, error) {
+	labels := prometheus.Labels{"method": "StateGraph", "op": "get"}
+
+	cacheTotals.With(labels).Inc()
+	cacheInFlight.With(labels).Inc()
+	defer cacheInFlight.With(labels).Dec()
+
+	hw, err := s.memory("StateGraph")
+	if err != nil {
+		cacheErrors.With(labels).Inc()
+		return nil, err
+	}
+
+	g, policy := hw.StateGraph()
+
+	states := g.States()
+
+	resp := &cacher.StateGraphResponse{States: make([]*cacher.StateTransitions, 0, len(states)), Policy: string(policy)}
+	for _, state := range states {
+		next := append([]string{}, g[state]...)
+		sort.Strings(next)
+
+		resp.States = append(resp.States, &cacher.StateTransitions{State: state, Next: next})
+	}
+
+	cacheHits.With(labels).Inc()
+
+	return resp, nil
+}
+
+// Fsck implements cacher.CacherServer.
+func (s *server) Fsck(ctx context.Context, in *cacher.FsckRequest) (*cacher.FsckResponse, error) {
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("repair", in.Repair))
+	logger.With("caller", callerName(ctx), "repair", in.Repair).Info("fsck")
+	labels := prometheus.Labels{"method": "Fsck", "op": ""}
+
+	cacheTotals.With(labels).Inc()
+	cacheInFlight.With(labels).Inc()
+	defer cacheInFlight.With(labels).Dec()
+
+	hw, err := s.memory("Fsck")
+	if err != nil {
+		cacheErrors.With(labels).Inc()
+		return nil, err
+	}
+
+	timer := prometheus.NewTimer(cacheDuration.With(labels))
+	r := fsck(hw, in.Repair)
+	timer.ObserveDuration()
+
+	resp := &cacher.FsckResponse{
+		Hardware: int64(r.Hardware),
+		Problems: make([]*cacher.IndexProblem, 0, len(r.Problems)),
+		Repaired: r.Repaired,
+	}
+	for _, p := range r.Problems {
+		resp.Problems = append(resp.Problems, &cacher.IndexProblem{Index: p.Index, Kind: p.Kind, Key: p.Key, Got: p.Got, Want: p.Want})
+	}
+
+	cacheHits.With(labels).Inc()
+
+	return resp, nil
+}
+
+// History implements cacher.CacherServer.
+func (s *server) History(ctx context.Context, in *cacher.HistoryRequest) (*cacher.HistoryResponse, error) {
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("ID", in.ID))
+	labels := prometheus.Labels{"method": "History", "op": "get"}
+
+	cacheTotals.With(labels).Inc()
+	cacheInFlight.With(labels).Inc()
+	defer cacheInFlight.With(labels).Dec()
+
+	hw, err := s.memory("History")
+	if err != nil {
+		cacheErrors.With(labels).Inc()
+		return nil, err
+	}
+
+	revs := hw.History(in.ID)
+
+	resp := &cacher.HistoryResponse{Revisions: make([]*cacher.Revision, 0, len(revs))}
+	for _, r := range revs {
+		resp.Revisions = append(resp.Revisions, &cacher.Revision{
+			JSON:     r.JSON,
+			Time:     r.Time.Unix(),
+			Source:   r.Source,
+			Deleted:  r.Deleted,
+			Revision: r.Number,
+		})
+	}
+
+	cacheHits.With(labels).Inc()
+
+	return resp, nil
 }
 
 // ALL implements cacher.CacherServer.
-func (s *server) All(_ *cacher.Empty, stream cacher.Cacher_AllServer) error {
+func (s *server) All(in *cacher.AllRequest, stream cacher.Cacher_AllServer) error {
 	labels := prometheus.Labels{"method": "All", "op": "get"}
 
 	cacheTotals.With(labels).Inc()
@@ -155,11 +768,64 @@ func (s *server) All(_ *cacher.Empty, stream cacher.Cacher_AllServer) error {
 		return errors.New("DB is not ready")
 	}
 
+	maybeGzip(stream.Context())
+
 	timer := prometheus.NewTimer(cacheDuration.With(labels))
 	defer timer.ObserveDuration()
-	err := s.hw.All(func(j string) error {
-		return stream.Send(&cacher.Hardware{JSON: j})
+
+	batchSize := int(in.BatchSize)
+	if batchSize < 1 {
+		batchSize = 1
+	}
+
+	newResponse := func(batch []string) *cacher.AllResponse {
+		if in.BatchSize > 1 {
+			return &cacher.AllResponse{Batch: batch}
+		}
+
+		return &cacher.AllResponse{JSON: batch[0]}
+	}
+
+	// the last message is held back so it can carry the next page token
+	var pending *cacher.AllResponse
+
+	batch := make([]string, 0, batchSize)
+	next, err := s.list(in, func(j string) error {
+		batch = append(batch, j)
+		if len(batch) < batchSize {
+			return nil
+		}
+
+		if pending != nil {
+			if err := stream.Send(pending); err != nil {
+				return err
+			}
+		}
+
+		pending = newResponse(batch)
+		batch = make([]string, 0, batchSize)
+
+		return nil
 	})
+
+	if err == nil && len(batch) > 0 {
+		if pending != nil {
+			err = stream.Send(pending)
+		}
+
+		pending = newResponse(batch)
+	}
+
+	// a page without any matches still needs to tell the client where to continue
+	if err == nil && pending == nil && next != "" {
+		pending = &cacher.AllResponse{}
+	}
+
+	if err == nil && pending != nil {
+		pending.NextPageToken = next
+		err = stream.Send(pending)
+	}
+
 	if err != nil {
 		cacheErrors.With(labels).Inc()
 		return err
@@ -170,10 +836,129 @@ func (s *server) All(_ *cacher.Empty, stream cacher.Cacher_AllServer) error {
 	return nil
 }
 
+// list calls fn with each record of the page of All described by in that matches its filter.
+// It returns the token of the next page, "" if this is the last one.
+// Invalid page tokens and filters are reported as InvalidArgument.
+func (s *server) list(in *cacher.AllRequest, fn func(string) error) (string, error) {
+	if in.PageSize < 0 || in.PageSize > maxPageSize {
+		return "", status.Errorf(codes.InvalidArgument, "page_size must be between 0 and %d", maxPageSize)
+	}
+
+	after, err := decodePageToken(in.PageToken)
+	if err != nil {
+		return "", status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	match, err := newFilter(in.FilterLanguage, in.Filter, s.filterLimits)
+	if err != nil {
+		return "", status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	mask, err := newFieldMask(in.FieldMask)
+	if err != nil {
+		return "", err
+	}
+
+	page := func(fn func(string) error) (string, bool, error) {
+		if hw, ok := s.store.(*hardware.Hardware); ok {
+			return hw.Page(after, int(in.PageSize), in.IncludeDeleted, fn)
+		}
+
+		if after != "" || in.PageSize > 0 || in.IncludeDeleted {
+			return "", false, status.Errorf(codes.Unimplemented, "paging and include_deleted are not supported by the %s store", storeName(s.store))
+		}
+
+		return "", false, s.store.All(fn)
+	}
+
+	var filterErr error
+
+	last, more, err := page(func(j string) error {
+		if match != nil {
+			ok, err := match(j)
+			if err != nil {
+				filterErr = err
+
+				return err
+			}
+
+			if !ok {
+				return nil
+			}
+		}
+
+		j, err := mask.apply(j)
+		if err != nil {
+			return err
+		}
+
+		return fn(j)
+	})
+
+	var invalid invalidFilterError
+	if errors.As(filterErr, &invalid) {
+		return "", status.Error(codes.InvalidArgument, invalid.Error())
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	if !more {
+		return "", nil
+	}
+
+	return encodePageToken(last), nil
+}
+
+// maxPageSize caps the page_size of All, as the memory store copies a whole page while holding its lock.
+const maxPageSize = 10000
+
+// encodePageToken returns an opaque token for resuming All after id.
+func encodePageToken(id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(id))
+}
+
+func decodePageToken(token string) (string, error) {
+	id, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return "", errors.New("invalid page_token")
+	}
+
+	return string(id), nil
+}
+
+// maybeGzip compresses the responses of the stream in ctx if the client accepts gzip.
+func maybeGzip(ctx context.Context) {
+	compressors, err := grpc.ClientSupportedCompressors(ctx)
+	if err != nil {
+		return
+	}
+
+	for _, c := range compressors {
+		if c == gzip.Name {
+			if err := grpc.SetSendCompressor(ctx, gzip.Name); err != nil {
+				logger.Error(errors.Wrap(err, "set gzip compressor"))
+			}
+
+			return
+		}
+	}
+}
+
 // Watch implements cacher.CacherServer.
 func (s *server) Watch(in *cacher.GetRequest, stream cacher.Cacher_WatchServer) error {
-	l := logger.With("id", in.ID)
-	ch := make(chan string, 1)
+	l := logger.With("id", in.ID, "caller", callerName(stream.Context()))
+
+	mask, err := newFieldMask(in.FieldMask)
+	if err != nil {
+		return err
+	}
+
+	ch, cancel := s.store.Subscribe(in.ID)
+	defer cancel()
+
+	evict := make(chan struct{})
 
 	s.watchLock.Lock()
 
@@ -183,7 +968,7 @@ func (s *server) Watch(in *cacher.GetRequest, stream cacher.Cacher_WatchServer)
 		close(old)
 	}
 
-	s.watch[in.ID] = ch
+	s.watch[in.ID] = evict
 	s.watchLock.Unlock()
 
 	labels := prometheus.Labels{"method": "Watch", "op": "watch"}
@@ -194,10 +979,9 @@ func (s *server) Watch(in *cacher.GetRequest, stream cacher.Cacher_WatchServer)
 	defer func() {
 		s.watchLock.Lock()
 
-		// Only delete and close if the existing channel matches
-		if s.watch[in.ID] == ch {
+		// Only delete if we weren't evicted, evict was closed already otherwise
+		if s.watch[in.ID] == evict {
 			delete(s.watch, in.ID)
-			close(ch)
 		}
 
 		s.watchLock.Unlock()
@@ -213,12 +997,17 @@ func (s *server) Watch(in *cacher.GetRequest, stream cacher.Cacher_WatchServer)
 		case <-stream.Context().Done():
 			l.Info("client disconnected")
 			return status.Error(codes.OK, "client disconnected")
-		case j, ok := <-ch:
-			if !ok {
-				l.Info("we are being evicted, goodbye")
+		case <-evict:
+			l.Info("we are being evicted, goodbye")
+
+			return status.Error(codes.Unknown, "evicted")
+		case j := <-ch:
+			j, err := mask.apply(j)
+			if err != nil {
+				cacheErrors.With(labels).Inc()
+				l.Error(err)
 
-				// ch was replaced and already closed
-				return status.Error(codes.Unknown, "evicted")
+				return err
 			}
 
 			hw.Reset()
@@ -236,6 +1025,44 @@ func (s *server) Watch(in *cacher.GetRequest, stream cacher.Cacher_WatchServer)
 	}
 }
 
+// Audit implements cacher.CacherServer.
+func (s *server) Audit(in *cacher.AuditRequest, stream cacher.Cacher_AuditServer) error {
+	labels := prometheus.Labels{"method": "Audit", "op": "get"}
+
+	cacheTotals.With(labels).Inc()
+	cacheInFlight.With(labels).Inc()
+	defer cacheInFlight.With(labels).Dec()
+
+	if s.auditLog == nil {
+		return status.Error(codes.FailedPrecondition, "audit log is not enabled")
+	}
+
+	timer := prometheus.NewTimer(cacheDuration.With(labels))
+	defer timer.ObserveDuration()
+
+	var since time.Time
+	if in.Since > 0 {
+		since = time.Unix(in.Since, 0)
+	}
+
+	err := s.auditLog.Query(strings.TrimSpace(strings.ToLower(in.ID)), since, int(in.Limit), func(e auditEntry) error {
+		b, err := json.Marshal(e)
+		if err != nil {
+			return errors.Wrap(err, "encode audit entry")
+		}
+
+		return errors.Wrap(stream.Send(&cacher.AuditEntry{JSON: string(b)}), "stream send")
+	})
+	if err != nil {
+		cacheErrors.With(labels).Inc()
+		return err
+	}
+
+	cacheHits.With(labels).Inc()
+
+	return nil
+}
+
 // Cert returns the public cert that can be served to clients.
 func (s *server) Cert() []byte {
 	return s.cert
@@ -0,0 +1,332 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/packethost/cacher/hardware"
+	"github.com/packethost/cacher/hardware/bolt"
+	"github.com/packethost/cacher/protos/cacher"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type pushStream struct {
+	grpc.ServerStream
+	in      []*cacher.PushRequest
+	summary *cacher.PushSummary
+}
+
+func (s *pushStream) Context() context.Context {
+	return context.Background()
+}
+
+func (s *pushStream) Recv() (*cacher.PushRequest, error) {
+	if len(s.in) == 0 {
+		return nil, io.EOF
+	}
+
+	in := s.in[0]
+	s.in = s.in[1:]
+
+	return in, nil
+}
+
+func (s *pushStream) SendAndClose(summary *cacher.PushSummary) error {
+	s.summary = summary
+
+	return nil
+}
+
+func TestPushStream(t *testing.T) {
+	assert := require.New(t)
+
+	const (
+		id1 = "00000000-0000-0000-0000-000000000001"
+		id2 = "00000000-0000-0000-0000-000000000002"
+	)
+
+	s := &server{store: hardware.New(), watch: map[string]chan struct{}{}}
+
+	j1 := `{"id":"` + id1 + `"}`
+	_, err := s.store.Add(j1)
+	assert.NoError(err)
+
+	ch1, cancel := s.store.Subscribe(id1)
+	defer cancel()
+
+	ch2, cancel := s.store.Subscribe(id2)
+	defer cancel()
+
+	stream := &pushStream{in: []*cacher.PushRequest{
+		{Data: `{ "id": "` + id1 + `" }`},
+		{Data: `{"id":"` + id2 + `"}`},
+		{Data: `not json`},
+		{Data: `{"id":"` + id1 + `","state":"active"}`, ExpectedRevision: 2},
+	}}
+	assert.NoError(s.PushStream(stream))
+
+	assert.Equal(int64(1), stream.summary.Accepted)
+	assert.Equal(int64(1), stream.summary.Unchanged)
+	assert.Equal(int64(2), stream.summary.Rejected)
+	assert.Len(stream.summary.Errors, 2)
+	assert.Equal(int64(2), stream.summary.Errors[0].Index)
+	assert.Equal(int32(codes.InvalidArgument), stream.summary.Errors[0].Code)
+	assert.Equal(int64(3), stream.summary.Errors[1].Index)
+	assert.Equal(int32(codes.FailedPrecondition), stream.summary.Errors[1].Code)
+
+	assert.Equal(`{"id":"`+id2+`"}`, <-ch2)
+	assert.Empty(ch1)
+}
+
+func TestWriteError(t *testing.T) {
+	assert := require.New(t)
+
+	const id1 = "00000000-0000-0000-0000-000000000001"
+
+	s := &server{store: hardware.New(), watch: map[string]chan struct{}{}}
+
+	for _, j := range []string{
+		`not json`,
+		`{"id":"1"}`,
+		`{"id":"` + id1 + `","ip_addresses":[{"address":"10.0.0"}]}`,
+		`{"id":"` + id1 + `","network_ports":[{"data":{"mac":"00:00"}}]}`,
+	} {
+		_, err := s.Push(context.Background(), &cacher.PushRequest{Data: j})
+		assert.Equal(codes.InvalidArgument, status.Code(err), j)
+	}
+
+	resp, err := s.PushBatch(context.Background(), &cacher.PushBatchRequest{Documents: []*cacher.PushRequest{
+		{Data: `{"id":"` + id1 + `"}`},
+		{Data: `{"id":"` + id1 + `","state":"active"}`},
+	}})
+	assert.NoError(err)
+	assert.Equal(int32(codes.InvalidArgument), resp.Results[1].Code)
+}
+
+func TestPushSchema(t *testing.T) {
+	assert := require.New(t)
+
+	v, err := hardware.NewValidator(hardware.LatestSchema, hardware.SchemaStrict)
+	assert.NoError(err)
+
+	s := &server{store: hardware.New(hardware.Schema(v)), watch: map[string]chan struct{}{}}
+
+	_, err = s.Push(context.Background(), &cacher.PushRequest{Data: `{"id":"00000000-0000-0000-0000-000000000001","state":1}`})
+
+	st := status.Convert(err)
+	assert.Equal(codes.InvalidArgument, st.Code())
+	assert.Len(st.Details(), 1)
+
+	br, ok := st.Details()[0].(*errdetails.BadRequest)
+	assert.True(ok)
+	assert.Len(br.FieldViolations, 2)
+	assert.Equal("", br.FieldViolations[0].Field)
+	assert.Contains(br.FieldViolations[0].Description, "network_ports")
+	assert.Equal("/state", br.FieldViolations[1].Field)
+}
+
+func TestStateGraph(t *testing.T) {
+	assert := require.New(t)
+
+	s := &server{store: hardware.New(hardware.Transitions(hardware.StateGraph{"b": {"c", "a"}}, hardware.TransitionsReject))}
+
+	resp, err := s.StateGraph(context.Background(), &cacher.Empty{})
+	assert.NoError(err)
+	assert.Equal("reject", resp.Policy)
+	assert.Len(resp.States, 3)
+	assert.Equal("a", resp.States[0].State)
+	assert.Empty(resp.States[0].Next)
+	assert.Equal("b", resp.States[1].State)
+	assert.Equal([]string{"a", "c"}, resp.States[1].Next)
+
+	_, err = s.store.Add(`{"id":"00000000-0000-0000-0000-000000000001","state":"a"}`)
+	assert.NoError(err)
+
+	_, err = s.Push(context.Background(), &cacher.PushRequest{Data: `{"id":"00000000-0000-0000-0000-000000000001","state":"b"}`})
+	assert.Equal(codes.FailedPrecondition, status.Code(err))
+}
+
+func TestFsck(t *testing.T) {
+	assert := require.New(t)
+
+	hw := hardware.New()
+	s := &server{store: hw}
+
+	_, err := hw.Add(`{"id":"00000000-0000-0000-0000-000000000001","ip_addresses":[{"address":"10.0.0.1"}]}`)
+	assert.NoError(err)
+
+	// as found by an earlier check
+	fsckProblems.With(prometheus.Labels{"index": hardware.IndexIP, "kind": hardware.Missing}).Set(3)
+
+	labels := prometheus.Labels{"method": "Fsck", "op": ""}
+	hits := testutil.ToFloat64(cacheHits.With(labels))
+
+	resp, err := s.Fsck(context.Background(), &cacher.FsckRequest{})
+	assert.NoError(err)
+	assert.Equal(int64(1), resp.Hardware)
+	assert.Empty(resp.Problems)
+	assert.False(resp.Repaired)
+
+	// every combination is still exported, at 0
+	assert.Equal(len(fsckIndexes)*len(fsckKinds), testutil.CollectAndCount(fsckProblems))
+	assert.Equal(0.0, testutil.ToFloat64(fsckProblems.With(prometheus.Labels{"index": hardware.IndexIP, "kind": hardware.Missing})))
+	assert.Equal(hits+1, testutil.ToFloat64(cacheHits.With(labels)))
+}
+
+func TestAsOfTime(t *testing.T) {
+	assert := require.New(t)
+
+	hw := hardware.New(hardware.Revisions(3))
+	s := &server{store: hw, ingestDone: true, watch: map[string]chan struct{}{}}
+	ctx := context.Background()
+
+	const (
+		j1 = `{"id":"00000000-0000-0000-0000-000000000001","ip_addresses":[{"address":"10.0.0.1"}]}`
+		j2 = `{"id":"00000000-0000-0000-0000-000000000001","ip_addresses":[{"address":"10.0.0.2"}]}`
+	)
+
+	for _, j := range []string{j1, j2} {
+		_, err := s.Push(ctx, &cacher.PushRequest{Data: j})
+		assert.NoError(err)
+	}
+
+	// both revisions are likely stored within the same second, which only as_of_time tells apart
+	revs := hw.History("00000000-0000-0000-0000-000000000001")
+	assert.Len(revs, 2)
+
+	got, err := s.ByIP(ctx, &cacher.GetRequest{IP: "10.0.0.1", AsOfTime: timestamppb.New(revs[0].Time)})
+	assert.NoError(err)
+	assert.Equal(j1, got.JSON)
+
+	got, err = s.ByID(ctx, &cacher.GetRequest{ID: "00000000-0000-0000-0000-000000000001", AsOfTime: timestamppb.New(revs[1].Time)})
+	assert.NoError(err)
+	assert.Equal(j2, got.JSON)
+
+	// as_of_time takes precedence
+	got, err = s.ByIP(ctx, &cacher.GetRequest{IP: "10.0.0.1", AsOf: 1, AsOfTime: timestamppb.New(revs[0].Time)})
+	assert.NoError(err)
+	assert.Equal(j1, got.JSON)
+
+	got, err = s.ByIP(ctx, &cacher.GetRequest{IP: "10.0.0.1", AsOf: 1})
+	assert.NoError(err)
+	assert.Empty(got.JSON)
+
+	_, err = s.ByMAC(ctx, &cacher.GetRequest{MAC: "00:00:00:00:00:01", AsOfTime: &timestamppb.Timestamp{Nanos: -1}})
+	assert.Equal(codes.InvalidArgument, status.Code(err))
+}
+
+func TestBoltServer(t *testing.T) {
+	assert := require.New(t)
+
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "cacher.db"))
+	assert.NoError(err)
+	defer db.Close()
+
+	s := &server{store: db, ingestDone: true, watch: map[string]chan struct{}{}}
+	ctx := context.Background()
+
+	const j = `{"id":"00000000-0000-0000-0000-000000000001","network_ports":[{"name":"eth0","data":{"mac":"00:00:00:00:00:01"}}]}`
+
+	resp, err := s.Push(ctx, &cacher.PushRequest{Data: j})
+	assert.NoError(err)
+	assert.Equal("00000000-0000-0000-0000-000000000001", resp.ID)
+
+	got, err := s.ByMAC(ctx, &cacher.GetRequest{MAC: "00:00:00:00:00:01"})
+	assert.NoError(err)
+	assert.Equal(j, got.JSON)
+
+	got, err = s.ByID(ctx, &cacher.GetRequest{ID: resp.ID})
+	assert.NoError(err)
+	assert.Equal(j, got.JSON)
+
+	// the features of the memory store alone are refused rather than ignored
+	_, err = s.Push(ctx, &cacher.PushRequest{Data: j, ExpectedRevision: 1})
+	assert.Equal(codes.Unimplemented, status.Code(err))
+
+	_, err = s.Patch(ctx, &cacher.PatchRequest{ID: resp.ID, Patch: `{}`})
+	assert.Equal(codes.Unimplemented, status.Code(err))
+
+	_, err = s.PushBatch(ctx, &cacher.PushBatchRequest{Documents: []*cacher.PushRequest{{Data: j}}})
+	assert.Equal(codes.Unimplemented, status.Code(err))
+
+	_, err = s.History(ctx, &cacher.HistoryRequest{ID: resp.ID})
+	assert.Equal(codes.Unimplemented, status.Code(err))
+
+	_, err = s.Fsck(ctx, &cacher.FsckRequest{})
+	assert.Equal(codes.Unimplemented, status.Code(err))
+
+	_, err = s.ByCIDR(ctx, &cacher.CIDRRequest{CIDR: "10.0.0.0/8"})
+	assert.Equal(codes.Unimplemented, status.Code(err))
+
+	_, err = s.ByID(ctx, &cacher.GetRequest{ID: resp.ID, AsOfTime: timestamppb.Now()})
+	assert.Equal(codes.Unimplemented, status.Code(err))
+
+	// /hardware can't page through it, so streams everything
+	w := httptest.NewRecorder()
+	s.listHandler(w, httptest.NewRequest(http.MethodGet, "/hardware", nil))
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Equal(j+"\n", w.Body.String())
+	assert.Empty(w.Header().Get("X-Next-Page-Token"))
+
+	w = httptest.NewRecorder()
+	s.listHandler(w, httptest.NewRequest(http.MethodGet, "/hardware?page_size=1", nil))
+	assert.Equal(http.StatusNotImplemented, w.Code)
+}
+
+func TestMemoryOnlySettings(t *testing.T) {
+	assert := require.New(t)
+
+	for _, k := range memoryOnly {
+		if v, ok := os.LookupEnv(k); ok {
+			os.Unsetenv(k)
+			t.Cleanup(func() { os.Setenv(k, v) })
+		}
+	}
+
+	assert.Empty(memoryOnlySettings())
+
+	t.Setenv("CACHER_TOMBSTONE_TTL", "0")
+	t.Setenv("CACHER_HISTORY_SIZE", "5")
+	assert.Equal([]string{"CACHER_HISTORY_SIZE", "CACHER_TOMBSTONE_TTL"}, memoryOnlySettings())
+}
+
+func TestMethodTables(t *testing.T) {
+	assert := require.New(t)
+
+	// the service descriptor generated for the Cacher server isn't exported, the one of the proto file is
+	service := cacher.File_cacher_proto.Services().ByName("Cacher")
+	assert.NotNil(service)
+
+	methods := map[string]bool{}
+	for i := 0; i < service.Methods().Len(); i++ {
+		methods[string(service.Methods().Get(i).Name())] = true
+	}
+
+	// a new method has to be given a scope and a limit class, even if admin and unlimited
+	assert.Contains(methods, "PushStream")
+
+	for m := range methods {
+		assert.Contains(methodScopes, m)
+		assert.Contains(limitClasses, m)
+	}
+
+	for m := range methodScopes {
+		assert.Contains(methods, m)
+	}
+
+	for m := range limitClasses {
+		assert.Contains(methods, m)
+	}
+}
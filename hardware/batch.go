@@ -0,0 +1,157 @@
+package hardware
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"inet.af/netaddr"
+)
+
+// Write is a document to store with Batch, only if the hardware is at Revision unless it is 0.
+type Write struct {
+	JSON     string
+	Revision uint64
+}
+
+// BatchError is returned by Batch when any of the documents could not be stored, in which case none were.
+// It holds an error per document, nil for the ones that could have been stored.
+type BatchError []error
+
+func (e BatchError) Error() string {
+	n := 0
+	first := -1
+
+	for i, err := range e {
+		if err != nil {
+			n++
+
+			if first < 0 {
+				first = i
+			}
+		}
+	}
+
+	return fmt.Sprintf("%d of %d documents failed, document %d: %v", n, len(e), first, e[first])
+}
+
+// ErrDuplicate is returned (wrapped) by Batch for documents with the same id as an earlier one.
+var ErrDuplicate = errors.New("duplicate id in batch")
+
+// Batch stores all of the writes, from source, or none of them.
+// Every document is parsed and checked against its expected revision, with TransitionsReject for illegal state
+// transitions and, with the Reject policy, for addresses of other hardware (including earlier documents of the batch)
+// before any is stored, all under a single lock.
+func (h *Hardware) Batch(writes []Write, source string) ([]Written, error) {
+	failed := false
+	errs := make(BatchError, len(writes))
+	docs := make([]*doc, len(writes))
+
+	for i, w := range writes {
+		docs[i], errs[i] = h.parse(w.JSON)
+		failed = failed || errs[i] != nil
+	}
+
+	h.mu.Lock()
+	ws, err := h.batch(docs, errs, failed, writes, source)
+	seq := h.seq
+	h.mu.Unlock()
+
+	h.publish(seq)
+
+	if err != nil {
+		return nil, err
+	}
+
+	js := make([]string, len(docs))
+	for i, d := range docs {
+		js[i] = d.j
+	}
+
+	h.notify(ws, js)
+
+	return ws, nil
+}
+
+// batch checks and stores the parsed docs of Batch, failed if any could not be parsed.
+// Must be called with the lock held.
+func (h *Hardware) batch(docs []*doc, errs BatchError, failed bool, writes []Write, source string) ([]Written, error) {
+	seen := map[id]bool{}
+	ipOwners := map[netaddr.IP]id{}
+	macOwners := map[mac]id{}
+
+	for i, d := range docs {
+		if d == nil {
+			continue
+		}
+
+		errs[i] = h.checkBatch(d, writes[i].Revision, source, seen, ipOwners, macOwners)
+		failed = failed || errs[i] != nil
+	}
+
+	if failed {
+		return nil, errs
+	}
+
+	ws := make([]Written, len(docs))
+	for i, d := range docs {
+		w, err := h.store(d, source, 0)
+		if err != nil {
+			// checkBatch rules out every failure of store
+			return nil, errors.Wrap(err, "store checked document")
+		}
+
+		ws[i] = w
+	}
+
+	return ws, nil
+}
+
+// checkBatch returns the error storing d would fail with, given the ids and addresses of the batch's earlier documents.
+// Must be called with the lock held.
+func (h *Hardware) checkBatch(
+	d *doc, rev uint64, source string, seen map[id]bool, ipOwners map[netaddr.IP]id, macOwners map[mac]id,
+) error {
+	v := d.id()
+	if seen[v] {
+		return errors.Wrap(ErrDuplicate, string(v))
+	}
+
+	seen[v] = true
+
+	if rev != 0 && h.hw[v].rev != rev {
+		return errors.Wrapf(ErrRevisionMismatch, "%s is at revision %d, not %d", v, h.hw[v].rev, rev)
+	}
+
+	// only rejected transitions fail, the others are counted and logged once by store
+	if h.transitions == TransitionsReject {
+		if err := h.checkPushedState(d, source); err != nil {
+			return err
+		}
+	}
+
+	if h.policy != Reject {
+		return nil
+	}
+
+	if err := h.checkConflicts(v, d.ips, d.macs); err != nil {
+		return err
+	}
+
+	for ip := range d.ips {
+		if owner, ok := ipOwners[ip]; ok {
+			return errors.Wrapf(ErrConflict, "%s %s belongs to %s", ConflictIP, ip, owner)
+		}
+
+		ipOwners[ip] = v
+	}
+
+	for m := range d.macs {
+		if owner, ok := macOwners[m]; ok {
+			return errors.Wrapf(ErrConflict, "%s %s belongs to %s", ConflictMAC, m, owner)
+		}
+
+		macOwners[m] = v
+	}
+
+	return nil
+}
@@ -0,0 +1,153 @@
+package hardware
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatch(t *testing.T) {
+	assert := require.New(t)
+
+	const (
+		id1 = "00000000-0000-0000-0000-000000000001"
+		id2 = "00000000-0000-0000-0000-000000000002"
+		id3 = "00000000-0000-0000-0000-000000000003"
+	)
+
+	hw := New()
+
+	_, err := hw.Add(conflictHW(id1, "10.0.0.1", "00:00:00:00:00:01"))
+	assert.NoError(err)
+
+	// a bad document fails the whole batch
+	_, err = hw.Batch([]Write{
+		{JSON: conflictHW(id2, "10.0.0.2", "00:00:00:00:00:02")},
+		{JSON: conflictHW(id3, "10.0.0.3", "not-a-mac")},
+		{JSON: conflictHW(id1, "10.0.0.4", "00:00:00:00:00:04"), Revision: 2},
+		{JSON: conflictHW(id2, "10.0.0.5", "00:00:00:00:00:05")},
+	}, FromPush)
+
+	var batchErr BatchError
+	assert.True(errors.As(err, &batchErr))
+	assert.Len(batchErr, 4)
+	assert.NoError(batchErr[0])
+	assert.Error(batchErr[1])
+	assert.ErrorIs(batchErr[2], ErrRevisionMismatch)
+	assert.ErrorIs(batchErr[3], ErrDuplicate)
+
+	j, err := hw.ByIP("10.0.0.2")
+	assert.NoError(err)
+	assert.Empty(j)
+
+	j, err = hw.ByIP("10.0.0.1")
+	assert.NoError(err)
+	assert.Contains(j, id1)
+
+	ws, err := hw.Batch([]Write{
+		{JSON: conflictHW(id2, "10.0.0.2", "00:00:00:00:00:02")},
+		{JSON: conflictHW(id1, "10.0.0.4", "00:00:00:00:00:04"), Revision: 1},
+	}, FromPush)
+	assert.NoError(err)
+	assert.Equal([]Written{
+		{ID: id2, Revision: 1, Seq: 1},
+		{ID: id1, Old: conflictHW(id1, "10.0.0.1", "00:00:00:00:00:01"), Revision: 2, Seq: 2},
+	}, ws)
+
+	j, err = hw.ByIP("10.0.0.4")
+	assert.NoError(err)
+	assert.Contains(j, id1)
+}
+
+func TestBatchReject(t *testing.T) {
+	assert := require.New(t)
+
+	const (
+		id1 = "00000000-0000-0000-0000-000000000001"
+		id2 = "00000000-0000-0000-0000-000000000002"
+	)
+
+	hw := New(OnConflict(Reject))
+
+	// documents of the same batch conflict with each other too
+	_, err := hw.Batch([]Write{
+		{JSON: conflictHW(id1, "10.0.0.1", "00:00:00:00:00:01")},
+		{JSON: conflictHW(id2, "10.0.0.1", "00:00:00:00:00:02")},
+	}, FromPush)
+
+	var batchErr BatchError
+	assert.True(errors.As(err, &batchErr))
+	assert.NoError(batchErr[0])
+	assert.ErrorIs(batchErr[1], ErrConflict)
+	assert.Empty(hw.hw)
+	assert.Empty(hw.byIP)
+}
+
+func TestBatchRejectTransition(t *testing.T) {
+	assert := require.New(t)
+
+	const (
+		id1 = "00000000-0000-0000-0000-000000000001"
+		id2 = "00000000-0000-0000-0000-000000000002"
+	)
+
+	state := func(v, state string) string {
+		return `{"id":"` + v + `","state":"` + state + `"}`
+	}
+
+	hw := New(Transitions(DefaultStateGraph(), TransitionsReject))
+
+	_, err := hw.Batch([]Write{{JSON: state(id1, "provisionable")}, {JSON: state(id2, "in_use")}}, FromPush)
+	assert.NoError(err)
+
+	ch, cancel := hw.Subscribe(id1)
+	defer cancel()
+
+	_, err = hw.Batch([]Write{{JSON: state(id1, "provisioning")}, {JSON: state(id2, "provisionable")}}, FromPush)
+
+	var batchErr BatchError
+	assert.True(errors.As(err, &batchErr))
+	assert.NoError(batchErr[0])
+	assert.ErrorIs(batchErr[1], ErrIllegalTransition)
+
+	got, err := hw.ByID(id1)
+	assert.NoError(err)
+	assert.Equal(state(id1, "provisionable"), got)
+	assert.Empty(ch)
+
+	// ingested batches aren't checked
+	_, err = hw.Batch([]Write{{JSON: state(id1, "provisioning")}, {JSON: state(id2, "provisionable")}}, FromIngest)
+	assert.NoError(err)
+}
+
+func TestAddBulk(t *testing.T) {
+	assert := require.New(t)
+
+	const (
+		id1 = "00000000-0000-0000-0000-000000000001"
+		id2 = "00000000-0000-0000-0000-000000000002"
+	)
+
+	hw := New()
+
+	ch, cancel := hw.Subscribe(id1)
+	defer cancel()
+
+	j1 := conflictHW(id1, "10.0.0.1", "00:00:00:00:00:01")
+	errs, err := hw.AddBulk([]string{j1, `{"id":"` + id2 + `","ip_addresses":[{"address":"x"}]}`})
+	assert.NoError(err)
+	assert.NoError(errs[0])
+	assert.ErrorIs(errs[1], ErrInvalidDocument)
+
+	got, err := hw.ByIP("10.0.0.1")
+	assert.NoError(err)
+	assert.Equal(j1, got)
+
+	// loading the store isn't watched, writes after it are
+	assert.Empty(ch)
+
+	_, err = hw.Add(`{"id":"` + id1 + `","state":"deleted"}`)
+	assert.NoError(err)
+	assert.Len(ch, 1)
+}
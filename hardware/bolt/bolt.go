@@ -0,0 +1,339 @@
+// Package bolt provides a hardware.Store persisted in a bbolt database file, so the cache is kept across restarts.
+package bolt
+
+import (
+	"bytes"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/packethost/cacher/hardware"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.etcd.io/bbolt"
+	"inet.af/netaddr"
+)
+
+var (
+	hwBucket  = []byte("hardware") // id => document
+	ipBucket  = []byte("ip")       // ip address => id
+	macBucket = []byte("mac")      // mac address => id
+)
+
+// Store is a hardware.Store persisted in a bbolt database.
+// Like the in memory store, an address belonging to more than one hardware is looked up as the last one stored with it,
+// but unlike it the address is forgotten, rather than handed back to the others, once that hardware no longer has it.
+type Store struct {
+	db        *bbolt.DB
+	feed      hardware.Feed
+	gauge     prometheus.Gauge
+	validator *hardware.Validator
+}
+
+// The Option type describes functions that operate on Store during Open.
+type Option func(*Store)
+
+// Gauge will set the gauge used to track the number of hardware stored.
+func Gauge(g prometheus.Gauge) Option {
+	return func(s *Store) {
+		s.gauge = g
+	}
+}
+
+// WatchMisses will set the counter of documents dropped because a subscriber wasn't keeping up.
+func WatchMisses(c prometheus.Counter) Option {
+	return func(s *Store) {
+		s.feed.Misses = c
+	}
+}
+
+// Schema will validate documents with v before storing them.
+func Schema(v *hardware.Validator) Option {
+	return func(s *Store) {
+		s.validator = v
+	}
+}
+
+// Open opens, creating it if needed, the database at path.
+// It fails if the database is not released by another process within a second.
+func Open(path string, options ...Option) (*Store, error) {
+	db, err := bbolt.Open(path, 0o600, &bbolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, errors.Wrap(err, "open bolt db")
+	}
+
+	s := &Store{db: db}
+	for _, opt := range options {
+		opt(s)
+	}
+
+	n := 0
+	err = db.Update(func(tx *bbolt.Tx) error {
+		for _, name := range [][]byte{hwBucket, ipBucket, macBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return errors.Wrapf(err, "create %s bucket", name)
+			}
+		}
+
+		n = tx.Bucket(hwBucket).Stats().KeyN
+
+		return nil
+	})
+	if err != nil {
+		db.Close()
+
+		return nil, err
+	}
+
+	if s.gauge != nil {
+		s.gauge.Set(float64(n))
+	}
+
+	return s, nil
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return errors.Wrap(s.db.Close(), "close bolt db")
+}
+
+// Add implements hardware.Store.
+// Concurrent calls are committed together, sharing the cost of syncing the database to disk.
+func (s *Store) Add(j string) (string, error) {
+	docs, errs, err := s.add([]string{j}, s.db.Batch, true)
+	if err != nil {
+		return "", err
+	}
+
+	if errs[0] != nil {
+		return "", errs[0]
+	}
+
+	return docs[0].ID, nil
+}
+
+// AddBulk implements hardware.BulkAdder, storing the valid documents in a single transaction.
+func (s *Store) AddBulk(js []string) ([]error, error) {
+	_, errs, err := s.add(js, s.db.Update, false)
+
+	return errs, err
+}
+
+// add stores the valid documents of js in a transaction run by commit, which may run it more than once like Batch does,
+// publishing them to subscribers if publish is set, and returns the error of each of the others.
+func (s *Store) add(js []string, commit func(func(*bbolt.Tx) error) error, publish bool) ([]hardware.Document, []error, error) {
+	docs := make([]hardware.Document, len(js))
+	errs := make([]error, len(js))
+
+	for i, j := range js {
+		if errs[i] = s.validator.Validate(j); errs[i] == nil {
+			docs[i], errs[i] = hardware.Parse(j)
+		}
+	}
+
+	change := 0
+
+	err := commit(func(tx *bbolt.Tx) error {
+		change = 0
+
+		for i, d := range docs {
+			if errs[i] != nil {
+				continue
+			}
+
+			c, err := put(tx, d, js[i])
+			if err != nil {
+				return err
+			}
+
+			change += c
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if s.gauge != nil {
+		s.gauge.Add(float64(change))
+	}
+
+	for i, d := range docs {
+		if publish && errs[i] == nil {
+			s.feed.Publish(strings.ToLower(d.ID), js[i])
+		}
+	}
+
+	return docs, errs, nil
+}
+
+// put stores j, parsed as d, and returns by how much the number of hardware stored changed.
+func put(tx *bbolt.Tx, d hardware.Document, j string) (int, error) {
+	k := []byte(strings.ToLower(d.ID))
+	hws := tx.Bucket(hwBucket)
+	change := 0
+
+	if og := hws.Get(k); og != nil {
+		if err := unindex(tx, k, string(og)); err != nil {
+			return 0, err
+		}
+
+		change--
+	}
+
+	if d.Deleted {
+		return change, errors.Wrap(hws.Delete(k), "delete hardware")
+	}
+
+	for _, ip := range d.IPs {
+		if err := tx.Bucket(ipBucket).Put([]byte(ip), k); err != nil {
+			return 0, errors.Wrap(err, "index ip")
+		}
+	}
+
+	for _, mac := range d.MACs {
+		if err := tx.Bucket(macBucket).Put([]byte(mac), k); err != nil {
+			return 0, errors.Wrap(err, "index mac")
+		}
+	}
+
+	return change + 1, errors.Wrap(hws.Put(k, []byte(j)), "put hardware")
+}
+
+// unindex removes the addresses of og, the document stored for k, that still point at k.
+func unindex(tx *bbolt.Tx, k []byte, og string) error {
+	d, err := hardware.Parse(og)
+	if err != nil {
+		return errors.Wrap(err, "parse stored hardware")
+	}
+
+	for _, b := range []struct {
+		name  []byte
+		addrs []string
+	}{{ipBucket, d.IPs}, {macBucket, d.MACs}} {
+		bucket := tx.Bucket(b.name)
+		for _, addr := range b.addrs {
+			if string(bucket.Get([]byte(addr))) != string(k) {
+				continue
+			}
+
+			if err := bucket.Delete([]byte(addr)); err != nil {
+				return errors.Wrapf(err, "unindex %s", b.name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Get implements hardware.Store.
+func (s *Store) Get(v string) (string, error) {
+	return s.get(nil, []byte(strings.TrimSpace(strings.ToLower(v))))
+}
+
+// ByIP implements hardware.Store.
+func (s *Store) ByIP(v string) (string, error) {
+	ip, ok := netaddr.FromStdIP(net.ParseIP(v))
+	if !ok {
+		return "", errors.New("failed to parse ip")
+	}
+
+	return s.get(ipBucket, []byte(ip.String()))
+}
+
+// ByMAC implements hardware.Store.
+func (s *Store) ByMAC(v string) (string, error) {
+	m, err := net.ParseMAC(strings.TrimSpace(strings.ToLower(v)))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse mac")
+	}
+
+	return s.get(macBucket, []byte(m.String()))
+}
+
+// get returns the hardware with the id stored for k in index, or with the id k if index is nil.
+func (s *Store) get(index, k []byte) (string, error) {
+	j := ""
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		if index != nil {
+			k = tx.Bucket(index).Get(k)
+			if k == nil {
+				return nil
+			}
+		}
+
+		// the value is only valid during the transaction, so it has to be copied
+		j = string(tx.Bucket(hwBucket).Get(k))
+
+		return nil
+	})
+
+	return j, errors.Wrap(err, "view bolt db")
+}
+
+// allPageSize is how many documents All reads per read transaction.
+const allPageSize = 100
+
+// All implements hardware.Store.
+// The documents are copied out of the database a page at a time, fn being called between the read transactions: a long
+// running one would keep the pages freed by writes from being reused, and make writes wait for it to end when the
+// database needs to grow. Hardware stored or deleted while All is going may or may not be seen.
+func (s *Store) All(fn func(string) error) error {
+	var after []byte
+
+	for {
+		page := make([]string, 0, allPageSize)
+
+		err := s.db.View(func(tx *bbolt.Tx) error {
+			c := tx.Bucket(hwBucket).Cursor()
+
+			k, v := c.First()
+			if after != nil {
+				k, v = c.Seek(after)
+				if bytes.Equal(k, after) {
+					k, v = c.Next()
+				}
+			}
+
+			for ; k != nil && len(page) < allPageSize; k, v = c.Next() {
+				// keys and values are only valid during the transaction, so they have to be copied
+				page = append(page, string(v))
+				after = append(after[:0], k...)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return errors.Wrap(err, "view bolt db")
+		}
+
+		for _, j := range page {
+			if err := fn(j); err != nil {
+				return errors.Wrap(err, "callback function returned an error")
+			}
+		}
+
+		if len(page) < allPageSize {
+			return nil
+		}
+	}
+}
+
+// Delete implements hardware.Store.
+func (s *Store) Delete(v string) error {
+	j, err := hardware.Deletion(strings.TrimSpace(strings.ToLower(v)))
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Add(j)
+
+	return err
+}
+
+// Subscribe implements hardware.Store.
+func (s *Store) Subscribe(v string) (<-chan string, func()) {
+	return s.feed.Subscribe(strings.TrimSpace(strings.ToLower(v)))
+}
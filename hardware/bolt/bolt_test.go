@@ -0,0 +1,99 @@
+package bolt
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/packethost/cacher/hardware"
+	"github.com/packethost/cacher/hardware/storetest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) hardware.Store {
+		s, err := Open(filepath.Join(t.TempDir(), "cacher.db"))
+		require.NoError(t, err)
+		t.Cleanup(func() { s.Close() })
+
+		return s
+	})
+}
+
+func TestReopen(t *testing.T) {
+	assert := require.New(t)
+	path := filepath.Join(t.TempDir(), "cacher.db")
+
+	s, err := Open(path)
+	assert.NoError(err)
+
+	j := `{"id":"00000000-0000-0000-0000-000000000001","ip_addresses":[{"address":"10.0.0.1"}]}`
+	_, err = s.Add(j)
+	assert.NoError(err)
+	assert.NoError(s.Close())
+
+	s, err = Open(path)
+	assert.NoError(err)
+	defer s.Close()
+
+	got, err := s.ByIP("10.0.0.1")
+	assert.NoError(err)
+	assert.Equal(j, got)
+}
+
+func TestSchema(t *testing.T) {
+	assert := require.New(t)
+
+	v, err := hardware.NewValidator(hardware.LatestSchema, hardware.SchemaStrict)
+	assert.NoError(err)
+
+	s, err := Open(filepath.Join(t.TempDir(), "cacher.db"), Schema(v))
+	assert.NoError(err)
+	defer s.Close()
+
+	_, err = s.Add(`{"id":"00000000-0000-0000-0000-000000000001","state":"in_use","ip_addresses":[{"address":"10.0.0.1"}]}`)
+	assert.ErrorIs(err, hardware.ErrSchema)
+
+	got, err := s.ByIP("10.0.0.1")
+	assert.NoError(err)
+	assert.Empty(got)
+}
+
+func TestAllPages(t *testing.T) {
+	assert := require.New(t)
+
+	s, err := Open(filepath.Join(t.TempDir(), "cacher.db"))
+	assert.NoError(err)
+	defer s.Close()
+
+	js := make([]string, 2*allPageSize+1)
+	for i := range js {
+		js[i] = fmt.Sprintf(`{"id":"%08d-0000-0000-0000-000000000000"}`, i)
+	}
+
+	ch, cancel := s.Subscribe("00000000-0000-0000-0000-000000000000")
+	defer cancel()
+
+	errs, err := s.AddBulk(js)
+	assert.NoError(err)
+	for _, err := range errs {
+		assert.NoError(err)
+	}
+
+	// loading the store isn't watched
+	assert.Empty(ch)
+
+	// fn runs outside of the read transactions, so it can write
+	var got []string
+	assert.NoError(s.All(func(j string) error {
+		got = append(got, j)
+		if len(got) > 1 {
+			return nil
+		}
+
+		_, err := s.Add(js[len(js)-1])
+
+		return err
+	}))
+	assert.Equal(js, got)
+}
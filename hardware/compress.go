@@ -0,0 +1,331 @@
+package hardware
+
+import (
+	"bytes"
+	"compress/flate"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultDictionarySize is the size of the dictionaries trained by Retrain, deflate can't make use of larger ones.
+const DefaultDictionarySize = 32 << 10
+
+// Compression will store documents, including tombstones and kept revisions, compressed with deflate using dict as a
+// preset dictionary, if any. Lookups and All decompress them transparently.
+// Documents sharing a lot with the dictionary compress a lot better, see TrainDictionary and Retrain.
+func Compression(dict []byte) Option {
+	return func(h *Hardware) {
+		h.compress = true
+		h.dict = dict
+	}
+}
+
+// CompressionSeconds will set the counter of the time spent compressing and decompressing documents, by op
+// ("compress" or "decompress").
+func CompressionSeconds(c *prometheus.CounterVec) Option {
+	return func(h *Hardware) {
+		h.compressionSeconds = c
+	}
+}
+
+// codec compresses and decompresses documents with a dictionary.
+// A nil codec leaves them as they are.
+type codec struct {
+	dict []byte
+
+	compressSeconds   prometheus.Counter
+	decompressSeconds prometheus.Counter
+
+	writers sync.Pool // *flate.Writer
+	readers sync.Pool // io.ReadCloser implementing flate.Resetter
+}
+
+func (h *Hardware) newCodec(dict []byte) *codec {
+	c := &codec{dict: dict}
+
+	if h.compressionSeconds != nil {
+		c.compressSeconds = h.compressionSeconds.With(prometheus.Labels{"op": "compress"})
+		c.decompressSeconds = h.compressionSeconds.With(prometheus.Labels{"op": "decompress"})
+	}
+
+	return c
+}
+
+func (c *codec) encode(j string) (string, error) {
+	if c == nil {
+		return j, nil
+	}
+
+	start := time.Now()
+
+	buf := &bytes.Buffer{}
+
+	w, ok := c.writers.Get().(*flate.Writer)
+	if ok {
+		w.Reset(buf)
+	} else {
+		var err error
+
+		w, err = flate.NewWriterDict(buf, flate.DefaultCompression, c.dict)
+		if err != nil {
+			return "", errors.Wrap(err, "create compressor")
+		}
+	}
+
+	defer c.writers.Put(w)
+
+	if _, err := io.WriteString(w, j); err != nil {
+		return "", errors.Wrap(err, "compress document")
+	}
+
+	if err := w.Close(); err != nil {
+		return "", errors.Wrap(err, "compress document")
+	}
+
+	if c.compressSeconds != nil {
+		c.compressSeconds.Add(time.Since(start).Seconds())
+	}
+
+	return buf.String(), nil
+}
+
+func (c *codec) decode(s string) (string, error) {
+	if c == nil || s == "" {
+		return s, nil
+	}
+
+	start := time.Now()
+
+	r, ok := c.readers.Get().(io.ReadCloser)
+	if ok {
+		if err := r.(flate.Resetter).Reset(strings.NewReader(s), c.dict); err != nil {
+			return "", errors.Wrap(err, "decompress document")
+		}
+	} else {
+		r = flate.NewReaderDict(strings.NewReader(s), c.dict)
+	}
+
+	defer c.readers.Put(r)
+
+	b := &strings.Builder{}
+	if _, err := io.Copy(b, r); err != nil { //nolint:gosec // documents were compressed by encode
+		return "", errors.Wrap(err, "decompress document")
+	}
+
+	if c.decompressSeconds != nil {
+		c.decompressSeconds.Add(time.Since(start).Seconds())
+	}
+
+	return b.String(), nil
+}
+
+// TrainDictionary returns a dictionary of up to size bytes for compressing documents like the samples.
+// It is made of the fragments of the samples, their members and keys, found in more than one of them, the ones saving
+// the most last as deflate can reference them more cheaply.
+func TrainDictionary(samples []string, size int) []byte {
+	counts := map[string]int{}
+
+	for _, s := range samples {
+		seen := map[string]bool{}
+
+		for _, f := range strings.Split(s, ",") {
+			frags := []string{f}
+			if i := strings.Index(f, `":`); i >= 0 {
+				frags = append(frags, f[:i+2])
+			}
+
+			for _, f := range frags {
+				if len(f) < 4 || seen[f] {
+					continue
+				}
+
+				seen[f] = true
+				counts[f]++
+			}
+		}
+	}
+
+	type fragment struct {
+		s     string
+		score int
+	}
+
+	frags := []fragment{}
+
+	for s, n := range counts {
+		if n > 1 {
+			frags = append(frags, fragment{s: s, score: n * len(s)})
+		}
+	}
+
+	sort.Slice(frags, func(i, j int) bool {
+		if frags[i].score != frags[j].score {
+			return frags[i].score > frags[j].score
+		}
+
+		return frags[i].s < frags[j].s
+	})
+
+	picked := []string{}
+	n := 0
+
+	for _, f := range frags {
+		if n+len(f.s)+1 > size {
+			continue
+		}
+
+		picked = append(picked, f.s)
+		n += len(f.s) + 1
+	}
+
+	dict := make([]byte, 0, n)
+	for i := len(picked) - 1; i >= 0; i-- {
+		dict = append(dict, picked[i]...)
+		dict = append(dict, ',')
+	}
+
+	return dict
+}
+
+// maxSamples is the number of stored documents Retrain trains dictionaries on.
+const maxSamples = 1000
+
+// Retrain replaces the compression dictionary with one of up to size bytes trained on the stored documents, and
+// recompresses every document with it. It does nothing unless Hardware was configured with Compression.
+// Writes wait for it to be done, lookups by id, ip and mac address and switch port don't.
+func (h *Hardware) Retrain(size int) error {
+	if !h.compress {
+		return nil
+	}
+
+	h.mu.Lock()
+	err := h.retrain(size)
+	seq := h.seq
+	h.mu.Unlock()
+
+	h.publish(seq)
+
+	return err
+}
+
+// retrain is Retrain, must be called with the lock held.
+func (h *Hardware) retrain(size int) error {
+	old := h.codec.Load()
+
+	step := len(h.ids)/maxSamples + 1
+	samples := make([]string, 0, maxSamples)
+
+	for i := 0; i < len(h.ids); i += step {
+		j, err := old.decode(h.hw[h.ids[i]].j)
+		if err != nil {
+			return err
+		}
+
+		samples = append(samples, j)
+	}
+
+	c := h.newCodec(TrainDictionary(samples, size))
+
+	// documents are shared by the hardware and its latest revision, so they are only recompressed once
+	recoded := map[string]string{}
+	recode := func(s string) (string, error) {
+		if r, ok := recoded[s]; ok {
+			return r, nil
+		}
+
+		j, err := old.decode(s)
+		if err != nil {
+			return "", err
+		}
+
+		r, err := c.encode(j)
+		if err != nil {
+			return "", err
+		}
+
+		recoded[s] = r
+
+		return r, nil
+	}
+
+	hws := make(map[id]string, len(h.hw))
+	for v, hw := range h.hw {
+		j, err := recode(hw.j)
+		if err != nil {
+			return err
+		}
+
+		hws[v] = j
+	}
+
+	history := make(map[id][]Revision, len(h.history))
+	for v, revs := range h.history {
+		history[v] = make([]Revision, len(revs))
+
+		for i, r := range revs {
+			j, err := recode(r.JSON)
+			if err != nil {
+				return err
+			}
+
+			r.JSON = j
+			history[v][i] = r
+		}
+	}
+
+	// nothing is changed until every document could be recompressed
+	for v, j := range hws {
+		hw := h.hw[v]
+		hw.j = j
+		h.hw[v] = hw
+		h.touch(v)
+	}
+
+	h.history = history
+	h.codec.Store(c)
+	h.recountBytes()
+
+	return nil
+}
+
+// DocumentBytes returns the size of the documents kept, including tombstones and revisions, and how much memory they
+// take as stored, which is less if they are compressed. It doesn't take the lock, the sizes being kept up to date by
+// the writes.
+func (h *Hardware) DocumentBytes() (raw, stored int) {
+	return int(h.rawBytes.Load()), int(h.storedBytes.Load())
+}
+
+// countBytes adds n times the size of the document stored as s, of size bytes uncompressed, to the document sizes.
+// Documents are shared by the hardware and its latest revision, so those are counted with the hardware and the other
+// revisions on their own. Must be called with the lock held.
+func (h *Hardware) countBytes(s string, size, n int) {
+	h.rawBytes.Add(int64(n * size))
+	h.storedBytes.Add(int64(n * len(s)))
+}
+
+// recountBytes sets the document sizes from every document kept, as counted by countBytes.
+// Must be called with the lock held.
+func (h *Hardware) recountBytes() {
+	var raw, stored int64
+
+	for _, hw := range h.hw {
+		raw += int64(hw.size)
+		stored += int64(len(hw.j))
+	}
+
+	for _, revs := range h.history {
+		for i := 0; i < len(revs)-1; i++ {
+			raw += int64(revs[i].size)
+			stored += int64(len(revs[i].JSON))
+		}
+	}
+
+	h.rawBytes.Store(raw)
+	h.storedBytes.Store(stored)
+}
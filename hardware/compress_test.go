@@ -0,0 +1,223 @@
+package hardware
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompression(t *testing.T) {
+	assert := require.New(t)
+
+	seconds := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "seconds"}, []string{"op"})
+	h := New(Compression(nil), CompressionSeconds(seconds), Revisions(3), Tombstones(time.Hour), Indexes(IndexSpec{Name: "state", Path: "state"}))
+
+	js, macs := benchHardware(50)
+	for _, j := range js {
+		_, err := h.Add(j[0])
+		assert.NoError(err)
+	}
+
+	_, err := h.Add(js[0][1])
+	assert.NoError(err)
+
+	assert.NotEqual(js[1][0], h.hw["00000001-0000-0000-0000-000000000000"].j)
+
+	got, err := h.ByMAC(macs[1])
+	assert.NoError(err)
+	assert.Equal(js[1][0], got)
+
+	got, err = h.ByIP("10.0.0.1")
+	assert.NoError(err)
+	assert.Equal(js[1][0], got)
+
+	got, err = h.ByID("00000000-0000-0000-0000-000000000000")
+	assert.NoError(err)
+	assert.Equal(js[0][1], got)
+
+	got, err = h.ByIDAsOf("00000000-0000-0000-0000-000000000000", time.Now())
+	assert.NoError(err)
+	assert.Equal(js[0][1], got)
+
+	got, err = h.ByMACAsOf(macs[1], time.Now())
+	assert.NoError(err)
+	assert.Equal(js[1][0], got)
+
+	revs := h.History("00000000-0000-0000-0000-000000000000")
+	assert.Len(revs, 2)
+	assert.Equal(js[0][0], revs[0].JSON)
+
+	byState, err := h.ByIndex("state", "provisioning")
+	assert.NoError(err)
+	assert.Equal([]string{js[0][1]}, byState)
+
+	all := []string{}
+	assert.NoError(h.All(func(j string) error {
+		all = append(all, j)
+
+		return nil
+	}))
+	assert.Len(all, 50)
+	assert.Equal(js[0][1], all[0])
+	assert.Equal(js[49][0], all[49])
+
+	patched, w, err := h.Patch("00000002-0000-0000-0000-000000000000", MergePatch, `{"state":"provisioning"}`, FromPush, 0)
+	assert.NoError(err)
+	assert.Equal(js[2][0], w.Old)
+	assert.Contains(patched, `"provisioning"`)
+
+	got, err = h.ByMAC(macs[2])
+	assert.NoError(err)
+	assert.Equal(patched, got)
+
+	raw, stored := h.DocumentBytes()
+	assert.Less(stored, raw)
+
+	assert.Greater(testutil.ToFloat64(seconds.WithLabelValues("compress")), 0.0)
+	assert.Greater(testutil.ToFloat64(seconds.WithLabelValues("decompress")), 0.0)
+}
+
+func TestRetrain(t *testing.T) {
+	assert := require.New(t)
+
+	h := New(Compression(nil), Revisions(2))
+
+	js, macs := benchHardware(200)
+	for _, j := range js {
+		_, err := h.Add(j[0])
+		assert.NoError(err)
+	}
+
+	raw, before := h.DocumentBytes()
+
+	s := h.snap.Load()
+
+	assert.NoError(h.Retrain(DefaultDictionarySize))
+
+	_, after := h.DocumentBytes()
+	assert.Less(after, before)
+	assert.Less(after, raw)
+
+	// documents stay shared with their revision
+	assert.Equal(unsafe.StringData(h.hw["00000001-0000-0000-0000-000000000000"].j), unsafe.StringData(h.history["00000001-0000-0000-0000-000000000000"][0].JSON))
+
+	for i, m := range macs {
+		got, err := h.ByMAC(m)
+		assert.NoError(err)
+		assert.Equal(js[i][0], got)
+	}
+
+	// snapshots published before decompress with their own dictionary
+	e, _ := s.hw.get(hashID("00000001-0000-0000-0000-000000000000"), "00000001-0000-0000-0000-000000000000")
+	got, err := s.codec.decode(e.j)
+	assert.NoError(err)
+	assert.Equal(js[1][0], got)
+
+	_, err = h.Add(js[3][1])
+	assert.NoError(err)
+
+	got, err = h.ByMAC(macs[3])
+	assert.NoError(err)
+	assert.Equal(js[3][1], got)
+
+	// without compression there is nothing to retrain
+	h = New()
+	_, err = h.Add(js[0][0])
+	assert.NoError(err)
+	assert.NoError(h.Retrain(DefaultDictionarySize))
+
+	raw, stored := h.DocumentBytes()
+	assert.Equal(len(js[0][0]), raw)
+	assert.Equal(raw, stored)
+}
+
+func TestTrainDictionary(t *testing.T) {
+	assert := require.New(t)
+
+	samples := []string{}
+	for i := 0; i < 10; i++ {
+		samples = append(samples, fmt.Sprintf(`{"id":"%d","plan":"c3.small.x86","facility":"ewr1","hostname":"host-%d"}`, i, i))
+	}
+
+	dict := string(TrainDictionary(samples, 1024))
+	assert.Contains(dict, `"plan":"c3.small.x86"`)
+	assert.Contains(dict, `"hostname":`)
+	assert.NotContains(dict, `host-1`)
+
+	// the fragments saving the most are last
+	assert.True(strings.HasSuffix(dict, `"plan":"c3.small.x86",`), dict)
+
+	assert.LessOrEqual(len(TrainDictionary(samples, 16)), 16)
+	assert.Empty(TrainDictionary(nil, 1024))
+}
+
+func TestDocumentBytes(t *testing.T) {
+	assert := require.New(t)
+
+	// the sizes kept up to date by the writes match the ones counted from scratch
+	check := func(h *Hardware) {
+		raw, stored := h.DocumentBytes()
+
+		h.mu.Lock()
+		h.recountBytes()
+		h.mu.Unlock()
+
+		wantRaw, wantStored := h.DocumentBytes()
+		assert.Equal(wantRaw, raw)
+		assert.Equal(wantStored, stored)
+	}
+
+	js, _ := benchHardware(5)
+	deleted := func(i int) string {
+		return fmt.Sprintf(`{"id":"%08d-0000-0000-0000-000000000000","state":"deleted"}`, i)
+	}
+
+	for _, h := range []*Hardware{
+		New(),
+		New(Revisions(2)),
+		New(Compression(nil), Revisions(2), Tombstones(time.Hour)),
+		New(Revisions(1), Tombstones(time.Hour)),
+	} {
+		for _, j := range js {
+			_, err := h.Add(j[0])
+			assert.NoError(err)
+		}
+		check(h)
+
+		for _, j := range js {
+			_, err := h.Add(j[1])
+			assert.NoError(err)
+		}
+		check(h)
+
+		_, err := h.Add(js[2][0])
+		assert.NoError(err)
+		check(h)
+
+		_, err = h.Add(deleted(1))
+		assert.NoError(err)
+		_, err = h.Add(deleted(2))
+		assert.NoError(err)
+		check(h)
+
+		_, err = h.Add(js[2][1])
+		assert.NoError(err)
+		check(h)
+
+		h.Sweep(time.Now().Add(2 * time.Hour))
+		check(h)
+
+		assert.NoError(h.Retrain(DefaultDictionarySize))
+		check(h)
+
+		raw, stored := h.DocumentBytes()
+		assert.Greater(raw, 0)
+		assert.Greater(stored, 0)
+	}
+}
@@ -0,0 +1,196 @@
+package hardware
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"inet.af/netaddr"
+)
+
+// ConflictPolicy decides which hardware a mac or ip address claimed by more than one hardware is looked up as.
+type ConflictPolicy string
+
+const (
+	// LastWriteWins looks the address up as the hardware most recently added with it.
+	LastWriteWins ConflictPolicy = "last-write-wins"
+	// FirstWriteWins looks the address up as the hardware that had it first, until that one drops it.
+	FirstWriteWins ConflictPolicy = "first-write-wins"
+	// Reject fails adding hardware with addresses that belong to another hardware.
+	Reject ConflictPolicy = "reject"
+)
+
+// ParseConflictPolicy returns the policy named s.
+func ParseConflictPolicy(s string) (ConflictPolicy, error) {
+	switch p := ConflictPolicy(s); p {
+	case LastWriteWins, FirstWriteWins, Reject:
+		return p, nil
+	}
+
+	return "", errors.Errorf("unknown conflict policy %q", s)
+}
+
+// ErrConflict is returned (wrapped) by Add and Swap when the Reject policy refuses a hardware.
+var ErrConflict = errors.New("address belongs to another hardware")
+
+// Kinds of conflicting addresses.
+const (
+	ConflictMAC = "mac"
+	ConflictIP  = "ip"
+)
+
+// Conflict is a mac or ip address claimed by more than one hardware.
+type Conflict struct {
+	Kind  string // ConflictMAC or ConflictIP
+	Value string
+	// Owner is the hardware the address is looked up as.
+	Owner string
+	// IDs are all the hardware claiming the address, sorted.
+	IDs []string
+}
+
+type claim struct {
+	kind  string
+	value string
+}
+
+// OnConflict will set the policy used to resolve conflicting addresses, LastWriteWins by default.
+func OnConflict(p ConflictPolicy) Option {
+	return func(h *Hardware) {
+		h.policy = p
+	}
+}
+
+// ConflictGauge will set the gauge used to track the number of conflicting addresses, labeled by kind.
+func ConflictGauge(g *prometheus.GaugeVec) Option {
+	return func(h *Hardware) {
+		h.conflictGauge = g
+	}
+}
+
+// addClaim records that v claims c, logging and counting new conflicts.
+func (h *Hardware) addClaim(c claim, v id) {
+	ids, ok := h.claims[c]
+	if !ok {
+		ids = map[id]bool{}
+		h.claims[c] = ids
+	}
+
+	if ids[v] {
+		return
+	}
+
+	ids[v] = true
+
+	if len(ids) < 2 {
+		return
+	}
+
+	if len(ids) == 2 && h.conflictGauge != nil {
+		h.conflictGauge.With(prometheus.Labels{"kind": c.kind}).Inc()
+	}
+
+	if h.logger != nil {
+		h.logger.With("kind", c.kind, "value", c.value, "id", v, "ids", h.claimIDs(c), "policy", h.policy).Info("address conflict")
+	}
+}
+
+// removeClaim records that v no longer claims c and returns another hardware still claiming it, or "" if none.
+func (h *Hardware) removeClaim(c claim, v id) id {
+	ids := h.claims[c]
+	if !ids[v] {
+		return ""
+	}
+
+	delete(ids, v)
+
+	if len(ids) == 1 && h.conflictGauge != nil {
+		h.conflictGauge.With(prometheus.Labels{"kind": c.kind}).Dec()
+	}
+
+	if len(ids) == 0 {
+		delete(h.claims, c)
+
+		return ""
+	}
+
+	return id(h.claimIDs(c)[0])
+}
+
+func (h *Hardware) claimIDs(c claim) []string {
+	ids := make([]string, 0, len(h.claims[c]))
+	for v := range h.claims[c] {
+		ids = append(ids, string(v))
+	}
+
+	sort.Strings(ids)
+
+	return ids
+}
+
+// checkConflicts returns an error if any of the addresses belong to another hardware than v.
+func (h *Hardware) checkConflicts(v id, ips map[netaddr.IP]IPMatch, macs map[mac]string) error {
+	for ip := range ips {
+		if owner, ok := h.byIP[ip]; ok && owner != v {
+			return h.reject(claim{kind: ConflictIP, value: ip.String()}, v, owner)
+		}
+	}
+
+	for mac := range macs {
+		if owner, ok := h.byMAC[mac]; ok && owner != v {
+			return h.reject(claim{kind: ConflictMAC, value: string(mac)}, v, owner)
+		}
+	}
+
+	return nil
+}
+
+func (h *Hardware) reject(c claim, v, owner id) error {
+	if h.logger != nil {
+		h.logger.With("kind", c.kind, "value", c.value, "id", v, "owner", owner).Info("address conflict rejected")
+	}
+
+	return errors.Wrapf(ErrConflict, "%s %s belongs to %s", c.kind, c.value, owner)
+}
+
+// wins reports whether v should own an address currently owned by owner, if any, under the conflict policy.
+func (h *Hardware) wins(owner id, ok bool, v id) bool {
+	return !ok || owner == v || h.policy != FirstWriteWins
+}
+
+// Conflicts returns the addresses claimed by more than one hardware, ordered by kind and value.
+func (h *Hardware) Conflicts() []Conflict {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	cs := []Conflict{}
+
+	for c, ids := range h.claims {
+		if len(ids) < 2 {
+			continue
+		}
+
+		conflict := Conflict{Kind: c.kind, Value: c.value, IDs: h.claimIDs(c)}
+
+		switch c.kind {
+		case ConflictMAC:
+			conflict.Owner = string(h.byMAC[mac(c.value)])
+		case ConflictIP:
+			if ip, err := netaddr.ParseIP(c.value); err == nil {
+				conflict.Owner = string(h.byIP[ip])
+			}
+		}
+
+		cs = append(cs, conflict)
+	}
+
+	sort.Slice(cs, func(i, j int) bool {
+		if cs[i].Kind != cs[j].Kind {
+			return cs[i].Kind < cs[j].Kind
+		}
+
+		return cs[i].Value < cs[j].Value
+	})
+
+	return cs
+}
@@ -0,0 +1,121 @@
+package hardware
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	conflictID1 = "00000000-0000-0000-0000-000000000001"
+	conflictID2 = "00000000-0000-0000-0000-000000000002"
+)
+
+func conflictHW(v, ip, mac string) string {
+	return `{"id":"` + v + `","ip_addresses":[{"address":"` + ip + `"}],"network_ports":[{"name":"eth0","data":{"mac":"` + mac + `"}}]}`
+}
+
+func TestConflicts(t *testing.T) {
+	for _, test := range []struct {
+		policy ConflictPolicy
+		owner  string // of the conflicting addresses
+	}{
+		{policy: LastWriteWins, owner: conflictID2},
+		{policy: FirstWriteWins, owner: conflictID1},
+	} {
+		t.Run(string(test.policy), func(t *testing.T) {
+			assert := require.New(t)
+
+			g := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "conflicts"}, []string{"kind"})
+			hw := New(OnConflict(test.policy), ConflictGauge(g))
+
+			hw1 := conflictHW(conflictID1, "10.0.0.1", "00:00:00:00:00:01")
+			hw2 := conflictHW(conflictID2, "10.0.0.1", "00:00:00:00:00:01")
+			for _, j := range []string{hw1, hw2} {
+				_, err := hw.Add(j)
+				assert.NoError(err)
+			}
+
+			assert.Equal([]Conflict{
+				{Kind: ConflictIP, Value: "10.0.0.1", Owner: test.owner, IDs: []string{conflictID1, conflictID2}},
+				{Kind: ConflictMAC, Value: "00:00:00:00:00:01", Owner: test.owner, IDs: []string{conflictID1, conflictID2}},
+			}, hw.Conflicts())
+			assert.Equal(1.0, testutil.ToFloat64(g.With(prometheus.Labels{"kind": ConflictIP})))
+			assert.Equal(1.0, testutil.ToFloat64(g.With(prometheus.Labels{"kind": ConflictMAC})))
+
+			j, err := hw.ByIP("10.0.0.1")
+			assert.NoError(err)
+			assert.Contains(j, test.owner)
+
+			// pushing the owner again changes nothing
+			_, err = hw.Add(hw.hw[id(test.owner)].j)
+			assert.NoError(err)
+			assert.Len(hw.Conflicts(), 2)
+
+			// once the owner drops the addresses they go to the other hardware
+			_, err = hw.Add(conflictHW(test.owner, "10.0.0.2", "00:00:00:00:00:02"))
+			assert.NoError(err)
+			assert.Empty(hw.Conflicts())
+			assert.Equal(0.0, testutil.ToFloat64(g.With(prometheus.Labels{"kind": ConflictIP})))
+
+			other := conflictID1
+			if test.owner == conflictID1 {
+				other = conflictID2
+			}
+
+			j, err = hw.ByIP("10.0.0.1")
+			assert.NoError(err)
+			assert.Contains(j, other)
+
+			j, err = hw.ByMAC("00:00:00:00:00:01")
+			assert.NoError(err)
+			assert.Contains(j, other)
+		})
+	}
+}
+
+func TestConflictsReject(t *testing.T) {
+	assert := require.New(t)
+
+	hw := New(OnConflict(Reject))
+
+	hw1 := conflictHW(conflictID1, "10.0.0.1", "00:00:00:00:00:01")
+	_, err := hw.Add(hw1)
+	assert.NoError(err)
+
+	for _, j := range []string{
+		conflictHW(conflictID2, "10.0.0.1", "00:00:00:00:00:02"),
+		conflictHW(conflictID2, "10.0.0.2", "00:00:00:00:00:01"),
+	} {
+		_, err = hw.Add(j)
+		assert.ErrorIs(err, ErrConflict)
+	}
+
+	// nothing of the rejected hardware was stored
+	assert.Len(hw.hw, 1)
+	assert.Len(hw.byIP, 1)
+	assert.Len(hw.byMAC, 1)
+	assert.Empty(hw.Conflicts())
+
+	// the owner can still change
+	_, err = hw.Add(conflictHW(conflictID1, "10.0.0.1", "00:00:00:00:00:03"))
+	assert.NoError(err)
+
+	_, err = hw.Add(conflictHW(conflictID2, "10.0.0.2", "00:00:00:00:00:01"))
+	assert.NoError(err)
+}
+
+func TestParseConflictPolicy(t *testing.T) {
+	assert := require.New(t)
+
+	for _, p := range []ConflictPolicy{LastWriteWins, FirstWriteWins, Reject} {
+		got, err := ParseConflictPolicy(string(p))
+		assert.NoError(err)
+		assert.Equal(p, got)
+	}
+
+	_, err := ParseConflictPolicy("random")
+	assert.Error(err)
+}
@@ -0,0 +1,512 @@
+package hardware
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"inet.af/netaddr"
+)
+
+// Indexes checked by Fsck.
+const (
+	IndexIP        = "ip"
+	IndexMAC       = "mac"
+	IndexPort      = "port"
+	IndexIDs       = "ids"       // the sorted ids All and Page go through
+	IndexCIDR      = "cidr"      // the sorted ip addresses ByCIDR searches
+	IndexSecondary = "secondary" // the indexes declared with Indexes, keyed by name=value
+	IndexClaims    = "claims"    // the hardware having each address, Conflicts reports, keyed by kind and address
+
+	// the lookups by id, ip and mac address and switch port as served from the published snapshot
+	IndexSnapshotHW   = "snapshot_hw"
+	IndexSnapshotIP   = "snapshot_ip"
+	IndexSnapshotMAC  = "snapshot_mac"
+	IndexSnapshotPort = "snapshot_port"
+)
+
+// Kinds of problems found by Fsck.
+const (
+	// Dangling is an entry for a key no hardware has.
+	Dangling = "dangling"
+	// Missing is a key of a hardware without an entry.
+	Missing = "missing"
+	// Mismatched is an entry for a key the hardware it points at doesn't have, while others do.
+	Mismatched = "mismatched"
+)
+
+// Problem is an entry of an index disagreeing with the stored hardware.
+type Problem struct {
+	Index string
+	Kind  string
+	Key   string // ip or mac address, switch/port, id, name=value or kind and address
+	Got   string // id the index has (revision for IndexSnapshotHW), "" if missing
+	Want  string // id of the first hardware having the key (revision for IndexSnapshotHW), "" if dangling
+}
+
+// FsckReport is what Fsck found.
+type FsckReport struct {
+	Hardware int       // number of hardware checked, tombstones included
+	Problems []Problem // ordered by index and key
+	Repaired bool
+}
+
+// Fsck rebuilds the lookups by ip and mac address and switch port, the sorted ids and ip addresses, the secondary
+// indexes and the claims of addresses from the stored hardware and reports where they disagree with the ones maintained
+// by Add. It also compares the snapshot lookups are served from with what was published to it, leaving out what was
+// written since.
+// An address claimed by more than one hardware is fine as long as it is looked up as one of them, whichever the
+// conflict policy picked. Likewise a value of a unique secondary index is only checked to point at a hardware having it,
+// as it is dropped once the last hardware added with it no longer has it.
+// With repair the indexes are fixed, an address whose entry is missing or mismatched going to the first hardware by id
+// having it, the snapshot is published again where it drifted, and lookups see the fixes once Fsck returns. Writes wait
+// for it to be done.
+func (h *Hardware) Fsck(repair bool) FsckReport {
+	if !repair {
+		h.mu.RLock()
+		r, _ := h.fsck()
+		h.mu.RUnlock()
+
+		return r
+	}
+
+	h.mu.Lock()
+
+	r, fixes := h.fsck()
+	for _, fix := range fixes {
+		fix()
+	}
+
+	if len(fixes) > 0 {
+		r.Repaired = true
+		// the fixes only mark the keys they changed or that drifted from the snapshot, no hardware is touched
+		h.seq++
+	}
+
+	seq := h.seq
+	h.mu.Unlock()
+
+	h.publish(seq)
+
+	return r
+}
+
+// fsck is Fsck without repairing, it returns the fixes for the problems found instead.
+// Must be called with the lock held.
+func (h *Hardware) fsck() (FsckReport, []func()) {
+	ips := map[netaddr.IP][]id{}
+	macs := map[mac][]id{}
+	ports := map[switchPort][]id{}
+
+	for v, hw := range h.hw {
+		for ip := range hw.ips {
+			ips[ip] = append(ips[ip], v)
+		}
+
+		for m := range hw.macs {
+			macs[m] = append(macs[m], v)
+		}
+
+		for sp := range hw.ports {
+			ports[sp] = append(ports[sp], v)
+		}
+	}
+
+	byPort := make(map[switchPort]id, len(h.byPort))
+	for sp, l := range h.byPort {
+		byPort[sp] = l.id
+	}
+
+	r := FsckReport{Hardware: len(h.hw)}
+
+	var fixes []func()
+
+	ps, fs := checkIndex(IndexIP, ips, h.byIP, netaddr.IP.String, h.setIP, h.deleteIP)
+	r.Problems = append(r.Problems, ps...)
+	fixes = append(fixes, fs...)
+
+	ps, fs = checkIndex(IndexMAC, macs, h.byMAC, func(m mac) string { return string(m) }, h.setMAC, h.deleteMAC)
+	r.Problems = append(r.Problems, ps...)
+	fixes = append(fixes, fs...)
+
+	ps, fs = checkIndex(IndexPort, ports, byPort, func(sp switchPort) string { return sp.sw + "/" + sp.port }, h.relinkPort, h.deletePort)
+	r.Problems = append(r.Problems, ps...)
+	fixes = append(fixes, fs...)
+
+	ids := make(map[id]id, len(h.hw))
+	for v := range h.hw {
+		ids[v] = v
+	}
+
+	ps = checkList(IndexIDs, ids, h.ids, func(v id) string { return string(v) })
+	r.Problems = append(r.Problems, ps...)
+
+	if len(ps) > 0 {
+		fixes = append(fixes, func() {
+			h.ids = h.ids[:0]
+			for v := range h.hw {
+				h.ids = append(h.ids, v)
+			}
+
+			sort.Slice(h.ids, func(i, j int) bool { return h.ids[i] < h.ids[j] })
+		})
+	}
+
+	// rebuilt from the ip lookup once it is repaired
+	ps = checkList(IndexCIDR, h.byIP, h.ips, netaddr.IP.String)
+	r.Problems = append(r.Problems, ps...)
+
+	if len(ps) > 0 {
+		fixes = append(fixes, func() {
+			h.ips = h.ips[:0]
+			for ip := range h.byIP {
+				h.ips = append(h.ips, ip)
+			}
+
+			sort.Slice(h.ips, func(i, j int) bool { return h.ips[i].Less(h.ips[j]) })
+		})
+	}
+
+	ps, fs = h.checkSecondary()
+	r.Problems = append(r.Problems, ps...)
+	fixes = append(fixes, fs...)
+
+	ps, fs = h.checkClaims()
+	r.Problems = append(r.Problems, ps...)
+	fixes = append(fixes, fs...)
+
+	ps, fs = h.checkSnapshot()
+	r.Problems = append(r.Problems, ps...)
+	fixes = append(fixes, fs...)
+
+	sort.Slice(r.Problems, func(i, j int) bool {
+		a, b := r.Problems[i], r.Problems[j]
+		if a.Index != b.Index {
+			return a.Index < b.Index
+		}
+
+		if a.Key != b.Key {
+			return a.Key < b.Key
+		}
+
+		if a.Got != b.Got {
+			return a.Got < b.Got
+		}
+
+		return a.Want < b.Want
+	})
+
+	return r, fixes
+}
+
+// checkIndex compares the index live with owners, the hardware having each key, and returns the problems found along
+// with the fixes calling set and del.
+func checkIndex[K comparable](
+	index string, owners map[K][]id, live map[K]id, key func(K) string, set func(K, id), del func(K),
+) ([]Problem, []func()) {
+	var (
+		ps    []Problem
+		fixes []func()
+	)
+
+	for k, vs := range owners {
+		sort.Slice(vs, func(i, j int) bool { return vs[i] < vs[j] })
+
+		got, ok := live[k]
+		if ok && contains(vs, got) {
+			continue
+		}
+
+		p := Problem{Index: index, Kind: Missing, Key: key(k), Want: string(vs[0])}
+		if ok {
+			p.Kind = Mismatched
+			p.Got = string(got)
+		}
+
+		ps = append(ps, p)
+
+		k, v := k, vs[0]
+		fixes = append(fixes, func() { set(k, v) })
+	}
+
+	for k, got := range live {
+		if _, ok := owners[k]; ok {
+			continue
+		}
+
+		ps = append(ps, Problem{Index: index, Kind: Dangling, Key: key(k), Got: string(got)})
+
+		k := k
+		fixes = append(fixes, func() { del(k) })
+	}
+
+	return ps, fixes
+}
+
+// checkList compares the sorted keys of want with list.
+func checkList[K comparable, V any](index string, want map[K]V, list []K, key func(K) string) []Problem {
+	var ps []Problem
+
+	seen := make(map[K]bool, len(list))
+	for _, k := range list {
+		if _, ok := want[k]; !ok || seen[k] {
+			ps = append(ps, Problem{Index: index, Kind: Dangling, Key: key(k)})
+		}
+
+		seen[k] = true
+	}
+
+	for k := range want {
+		if !seen[k] {
+			ps = append(ps, Problem{Index: index, Kind: Missing, Key: key(k)})
+		}
+	}
+
+	return ps
+}
+
+// checkSecondary compares the secondary indexes with the values of the stored hardware.
+func (h *Hardware) checkSecondary() ([]Problem, []func()) {
+	var (
+		ps    []Problem
+		fixes []func()
+	)
+
+	for name, idx := range h.indexes {
+		owners := map[string]map[id]bool{}
+
+		for v, hw := range h.hw {
+			for val := range hw.vals[name] {
+				if owners[val] == nil {
+					owners[val] = map[id]bool{}
+				}
+
+				owners[val][v] = true
+			}
+		}
+
+		idx, key := idx, func(val string) string { return name + "=" + val }
+
+		for val, ids := range idx.ids {
+			for v := range ids {
+				if owners[val][v] {
+					continue
+				}
+
+				ps = append(ps, Problem{Index: IndexSecondary, Kind: Dangling, Key: key(val), Got: string(v)})
+
+				val, v := val, v
+				fixes = append(fixes, func() {
+					delete(idx.ids[val], v)
+
+					if len(idx.ids[val]) == 0 {
+						delete(idx.ids, val)
+					}
+				})
+			}
+		}
+
+		// unique indexes only keep the last hardware added with a value
+		if idx.spec.Unique {
+			continue
+		}
+
+		for val, vs := range owners {
+			for v := range vs {
+				if idx.ids[val][v] {
+					continue
+				}
+
+				ps = append(ps, Problem{Index: IndexSecondary, Kind: Missing, Key: key(val), Want: string(v)})
+
+				val, v := val, v
+				fixes = append(fixes, func() {
+					if idx.ids[val] == nil {
+						idx.ids[val] = map[id]bool{}
+					}
+
+					idx.ids[val][v] = true
+				})
+			}
+		}
+	}
+
+	return ps, fixes
+}
+
+// checkClaims compares the claims of addresses with the addresses of the stored hardware.
+func (h *Hardware) checkClaims() ([]Problem, []func()) {
+	want := map[claim]map[id]bool{}
+	add := func(c claim, v id) {
+		if want[c] == nil {
+			want[c] = map[id]bool{}
+		}
+
+		want[c][v] = true
+	}
+
+	for v, hw := range h.hw {
+		for ip := range hw.ips {
+			add(claim{kind: ConflictIP, value: ip.String()}, v)
+		}
+
+		for m := range hw.macs {
+			add(claim{kind: ConflictMAC, value: string(m)}, v)
+		}
+	}
+
+	var (
+		ps    []Problem
+		fixes []func()
+	)
+
+	for c, ids := range h.claims {
+		for v := range ids {
+			if want[c][v] {
+				continue
+			}
+
+			ps = append(ps, Problem{Index: IndexClaims, Kind: Dangling, Key: c.kind + " " + c.value, Got: string(v)})
+
+			c, v := c, v
+			fixes = append(fixes, func() { h.removeClaim(c, v) })
+		}
+	}
+
+	for c, ids := range want {
+		for v := range ids {
+			if h.claims[c][v] {
+				continue
+			}
+
+			ps = append(ps, Problem{Index: IndexClaims, Kind: Missing, Key: c.kind + " " + c.value, Want: string(v)})
+
+			c, v := c, v
+			fixes = append(fixes, func() { h.addClaim(c, v) })
+		}
+	}
+
+	return ps, fixes
+}
+
+// published is what the snapshot keeps of a hardware that can be compared.
+type published struct {
+	j       string
+	rev     uint64
+	deleted time.Time
+}
+
+// checkSnapshot compares the published snapshot with the locked maps it is copied from, leaving out the keys written to
+// since, and returns the fixes publishing the others again.
+func (h *Hardware) checkSnapshot() ([]Problem, []func()) {
+	s := h.snap.Load()
+
+	hws := make(map[id]published, len(h.hw))
+	for v, hw := range h.hw {
+		hws[v] = published{j: hw.j, rev: hw.rev, deleted: h.tombstones[v]}
+	}
+
+	snapHW := map[id]published{}
+	for _, shard := range s.hw {
+		for v, e := range shard {
+			snapHW[v] = published{j: e.j, rev: e.rev, deleted: e.deleted}
+		}
+	}
+
+	rev := func(p published) string { return strconv.FormatUint(p.rev, 10) }
+	self := func(v id) string { return string(v) }
+
+	var ps []Problem
+
+	p, fixes := checkPublished(IndexSnapshotHW, snapHW, hws, h.view.ids, self, rev)
+	ps = append(ps, p...)
+
+	p, fs := checkPublished(IndexSnapshotIP, s.byIP.flatten(), h.byIP, h.view.ips, netaddr.IP.String, self)
+	ps = append(ps, p...)
+	fixes = append(fixes, fs...)
+
+	p, fs = checkPublished(IndexSnapshotMAC, s.byMAC.flatten(), h.byMAC, h.view.macs, func(m mac) string { return string(m) }, self)
+	ps = append(ps, p...)
+	fixes = append(fixes, fs...)
+
+	p, fs = checkPublished(IndexSnapshotPort, s.byPort.flatten(), h.byPort, h.view.ports,
+		func(sp switchPort) string { return sp.sw + "/" + sp.port }, func(l portLink) string { return string(l.id) })
+	ps = append(ps, p...)
+	fixes = append(fixes, fs...)
+
+	return ps, fixes
+}
+
+// checkPublished compares pub, as published, with live, leaving out the keys in dirty, and returns the fixes marking
+// the keys that differ as dirty so they are published again.
+func checkPublished[K, V comparable](
+	index string, pub, live map[K]V, dirty map[K]bool, key func(K) string, val func(V) string,
+) ([]Problem, []func()) {
+	var (
+		ps    []Problem
+		fixes []func()
+	)
+
+	mark := func(k K) {
+		fixes = append(fixes, func() { dirty[k] = true })
+	}
+
+	for k, want := range live {
+		got, ok := pub[k]
+		if dirty[k] || (ok && got == want) {
+			continue
+		}
+
+		p := Problem{Index: index, Kind: Missing, Key: key(k), Want: val(want)}
+		if ok {
+			p.Kind = Mismatched
+			p.Got = val(got)
+		}
+
+		ps = append(ps, p)
+		mark(k)
+	}
+
+	for k, got := range pub {
+		if _, ok := live[k]; ok || dirty[k] {
+			continue
+		}
+
+		ps = append(ps, Problem{Index: index, Kind: Dangling, Key: key(k), Got: val(got)})
+		mark(k)
+	}
+
+	return ps, fixes
+}
+
+func contains(vs []id, v id) bool {
+	for _, w := range vs {
+		if w == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+// relinkPort links sp to the port of the hardware with id v connected to it.
+func (h *Hardware) relinkPort(sp switchPort, v id) {
+	l := portLink{id: v}
+
+	if j, err := h.codec.Load().decode(h.hw[v].j); err == nil {
+		hw := hardware{}
+		if json.Unmarshal([]byte(j), &hw) == nil {
+			for _, port := range hw.Ports {
+				for _, cp := range port.ConnectedPorts {
+					if cp.Name == sp.port && strings.EqualFold(cp.Hardware.Hostname, sp.sw) {
+						l.port = port.Name
+					}
+				}
+			}
+		}
+	}
+
+	h.setPort(sp, l)
+}
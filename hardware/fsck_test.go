@@ -0,0 +1,175 @@
+package hardware
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"inet.af/netaddr"
+)
+
+func TestFsck(t *testing.T) {
+	assert := require.New(t)
+
+	const (
+		id1 = "00000000-0000-0000-0000-000000000001"
+		id2 = "00000000-0000-0000-0000-000000000002"
+		id3 = "00000000-0000-0000-0000-000000000003"
+	)
+
+	j1 := `{"id":"` + id1 + `","ip_addresses":[{"address":"10.0.0.1"}],"network_ports":[{"name":"eth0","data":{"mac":"00:00:00:00:00:01"},` +
+		`"connected_ports":[{"name":"xe-0/0/1","hardware":{"hostname":"Leaf-1"}}]}]}`
+	j2 := `{"id":"` + id2 + `","ip_addresses":[{"address":"10.0.0.2"}],"network_ports":[{"name":"eth0","data":{"mac":"00:00:00:00:00:02"}}]}`
+	// claims the address of id1, which is fine
+	j3 := `{"id":"` + id3 + `","ip_addresses":[{"address":"10.0.0.1"}]}`
+
+	h := New(Compression(nil))
+	for _, j := range []string{j1, j2, j3} {
+		_, err := h.Add(j)
+		assert.NoError(err)
+	}
+
+	r := h.Fsck(false)
+	assert.Equal(3, r.Hardware)
+	assert.Empty(r.Problems)
+	assert.False(r.Repaired)
+
+	ip2 := netaddr.MustParseIP("10.0.0.2")
+	ip9 := netaddr.MustParseIP("10.0.0.9")
+
+	// drift every index the way bad bookkeeping would
+	h.mu.Lock()
+	h.setIP(ip2, id(id1))
+	h.setIP(ip9, id(id2))
+	h.deleteMAC("00:00:00:00:00:01")
+	h.deletePort(switchPort{sw: "leaf-1", port: "xe-0/0/1"})
+	h.ips = h.ips[1:]
+	h.removeID(id(id3))
+	h.seq++
+	seq := h.seq
+	h.mu.Unlock()
+	h.publish(seq)
+
+	got, err := h.ByIP("10.0.0.2")
+	assert.NoError(err)
+	assert.Equal(j1, got)
+
+	r = h.Fsck(false)
+	assert.Equal([]Problem{
+		{Index: IndexCIDR, Kind: Missing, Key: "10.0.0.1"},
+		{Index: IndexIDs, Kind: Missing, Key: id3},
+		{Index: IndexIP, Kind: Mismatched, Key: "10.0.0.2", Got: id1, Want: id2},
+		{Index: IndexIP, Kind: Dangling, Key: "10.0.0.9", Got: id2},
+		{Index: IndexMAC, Kind: Missing, Key: "00:00:00:00:00:01", Want: id1},
+		{Index: IndexPort, Kind: Missing, Key: "leaf-1/xe-0/0/1", Want: id1},
+	}, r.Problems)
+	assert.False(r.Repaired)
+
+	// checking alone fixes nothing
+	got, err = h.ByIP("10.0.0.2")
+	assert.NoError(err)
+	assert.Equal(j1, got)
+
+	r = h.Fsck(true)
+	assert.Len(r.Problems, 6)
+	assert.True(r.Repaired)
+
+	r = h.Fsck(false)
+	assert.Empty(r.Problems)
+
+	got, err = h.ByIP("10.0.0.2")
+	assert.NoError(err)
+	assert.Equal(j2, got)
+
+	got, err = h.ByIP("10.0.0.9")
+	assert.NoError(err)
+	assert.Empty(got)
+
+	got, err = h.ByMAC("00:00:00:00:00:01")
+	assert.NoError(err)
+	assert.Equal(j1, got)
+
+	got, port, err := h.ByPort("leaf-1", "xe-0/0/1")
+	assert.NoError(err)
+	assert.Equal(j1, got)
+	assert.Equal("eth0", port)
+
+	ms, err := h.ByCIDR("10.0.0.0/24")
+	assert.NoError(err)
+	assert.Len(ms, 2)
+
+	all := 0
+	assert.NoError(h.All(func(string) error {
+		all++
+
+		return nil
+	}))
+	assert.Equal(3, all)
+
+	assert.False(h.Fsck(true).Repaired)
+}
+
+func TestFsckSnapshotIndexesClaims(t *testing.T) {
+	assert := require.New(t)
+
+	const (
+		id1 = "00000000-0000-0000-0000-000000000001"
+		id2 = "00000000-0000-0000-0000-000000000002"
+	)
+
+	j1 := `{"id":"` + id1 + `","plan":{"slug":"c3"},"ip_addresses":[{"address":"10.0.0.1"}]}`
+	j2 := `{"id":"` + id2 + `","plan":{"slug":"c3"},"ip_addresses":[{"address":"10.0.0.2"}]}`
+
+	h := New(Compression(nil), Indexes(IndexSpec{Name: "plan", Path: "plan.slug"}))
+	for _, j := range []string{j1, j2} {
+		_, err := h.Add(j)
+		assert.NoError(err)
+	}
+
+	assert.Empty(h.Fsck(false).Problems)
+
+	ip1 := netaddr.MustParseIP("10.0.0.1")
+	ip2 := netaddr.MustParseIP("10.0.0.2")
+
+	// drift the published snapshot behind the back of publish, and the secondary index and claims
+	h.mu.Lock()
+	s := h.snap.Load()
+	s.byIP[hashIP(ip2)%nshards][ip2] = id(id1)
+	delete(s.hw[hashID(id(id2))%nshards], id(id2))
+	delete(h.indexes["plan"].ids["c3"], id(id2))
+	h.indexes["plan"].ids["c4"] = map[id]bool{id(id1): true}
+	h.removeClaim(claim{kind: ConflictIP, value: ip1.String()}, id(id1))
+	h.addClaim(claim{kind: ConflictIP, value: ip2.String()}, id(id1))
+	h.mu.Unlock()
+
+	got, err := h.ByIP("10.0.0.2")
+	assert.NoError(err)
+	assert.Equal(j1, got)
+
+	r := h.Fsck(false)
+	assert.Equal([]Problem{
+		{Index: IndexClaims, Kind: Missing, Key: "ip 10.0.0.1", Want: id1},
+		{Index: IndexClaims, Kind: Dangling, Key: "ip 10.0.0.2", Got: id1},
+		{Index: IndexSecondary, Kind: Missing, Key: "plan=c3", Want: id2},
+		{Index: IndexSecondary, Kind: Dangling, Key: "plan=c4", Got: id1},
+		{Index: IndexSnapshotHW, Kind: Missing, Key: id2, Want: "1"},
+		{Index: IndexSnapshotIP, Kind: Mismatched, Key: "10.0.0.2", Got: id1, Want: id2},
+	}, r.Problems)
+
+	r = h.Fsck(true)
+	assert.Len(r.Problems, 6)
+	assert.True(r.Repaired)
+	assert.Empty(h.Fsck(false).Problems)
+
+	got, err = h.ByIP("10.0.0.2")
+	assert.NoError(err)
+	assert.Equal(j2, got)
+
+	got, err = h.ByID(id2)
+	assert.NoError(err)
+	assert.Equal(j2, got)
+
+	plans, err := h.ByIndex("plan", "c3")
+	assert.NoError(err)
+	assert.Len(plans, 2)
+	assert.Empty(h.Conflicts())
+}
@@ -0,0 +1,207 @@
+package hardware
+
+import (
+	"net"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"inet.af/netaddr"
+)
+
+// Where revisions come from.
+const (
+	FromIngest = "ingest"
+	FromPush   = "push"
+)
+
+// ErrRevisionMismatch is returned (wrapped) by CompareAndSwap when the hardware is not at the expected revision.
+var ErrRevisionMismatch = errors.New("revision mismatch")
+
+// Revision is a version of a hardware document as it was stored.
+type Revision struct {
+	Number  uint64 // counts the versions stored for the id, from 1
+	JSON    string
+	Time    time.Time
+	Source  string // FromIngest or FromPush
+	Deleted bool
+
+	size int // of JSON uncompressed, which it is not while kept with Compression
+	// the addresses of the revision as parsed when it was stored, for ByIPAsOf and ByMACAsOf to search without decoding
+	// JSON, shared with the stored hardware and never modified
+	ips  map[netaddr.IP]IPMatch
+	macs map[mac]string
+}
+
+// Revisions will set how many of the most recent revisions of each hardware are kept, none by default.
+func Revisions(n int) Option {
+	return func(h *Hardware) {
+		h.revisions = n
+	}
+}
+
+// record appends a revision of v, dropping the oldest ones beyond the limit.
+// Must be called with the lock held.
+func (h *Hardware) record(v id, r Revision) {
+	if h.revisions < 1 {
+		return
+	}
+
+	revs := h.history[v]
+	if n := len(revs); n > 0 {
+		// no longer the latest, so no longer counted with the hardware
+		h.countBytes(revs[n-1].JSON, revs[n-1].size, 1)
+	}
+
+	revs = append(revs, r)
+	h.indexRevision(v, &r, 1)
+
+	if len(revs) > h.revisions {
+		for _, dropped := range revs[:len(revs)-h.revisions] {
+			h.countBytes(dropped.JSON, dropped.size, -1)
+			h.indexRevision(v, &dropped, -1)
+		}
+
+		revs = append([]Revision(nil), revs[len(revs)-h.revisions:]...)
+	}
+
+	h.history[v] = revs
+}
+
+// forget drops the history of v.
+// Must be called with the lock held.
+func (h *Hardware) forget(v id) {
+	revs := h.history[v]
+	for i := range revs {
+		if i < len(revs)-1 {
+			h.countBytes(revs[i].JSON, revs[i].size, -1)
+		}
+
+		h.indexRevision(v, &revs[i], -1)
+	}
+
+	delete(h.history, v)
+}
+
+// indexRevision adds n to the count of the revisions of v having each of the addresses of r.
+// Must be called with the lock held.
+func (h *Hardware) indexRevision(v id, r *Revision, n int) {
+	countPast(h.pastIPs, r.ips, v, n)
+	countPast(h.pastMACs, r.macs, v, n)
+}
+
+func countPast[K comparable, V any](past map[K]map[id]int, keys map[K]V, v id, n int) {
+	for k := range keys {
+		ids := past[k]
+		if ids == nil {
+			ids = map[id]int{}
+			past[k] = ids
+		}
+
+		ids[v] += n
+		if ids[v] > 0 {
+			continue
+		}
+
+		delete(ids, v)
+
+		if len(ids) == 0 {
+			delete(past, k)
+		}
+	}
+}
+
+// History returns the kept revisions of the hardware with the given id, oldest first.
+func (h *Hardware) History(v string) []Revision {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	v = strings.TrimSpace(strings.ToLower(v))
+
+	revs := append([]Revision(nil), h.history[id(v)]...)
+	for i, r := range revs {
+		// corrupt revisions can't be stored in the first place
+		revs[i].JSON, _ = h.codec.Load().decode(r.JSON)
+	}
+
+	return revs
+}
+
+// asOf returns the revision of v stored at t, if it is still kept.
+// Must be called with the lock held.
+func (h *Hardware) asOf(v id, t time.Time) (Revision, bool) {
+	revs := h.history[v]
+	for i := len(revs) - 1; i >= 0; i-- {
+		if !revs[i].Time.After(t) {
+			return revs[i], true
+		}
+	}
+
+	return Revision{}, false
+}
+
+// ByIDAsOf returns the hardware with the given id as it was at t, "" if it did not exist, was deleted or its revision
+// from then is no longer kept.
+func (h *Hardware) ByIDAsOf(v string, t time.Time) (string, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	v = strings.TrimSpace(strings.ToLower(v))
+
+	r, ok := h.asOf(id(v), t)
+	if !ok || r.Deleted {
+		return "", nil
+	}
+
+	return h.codec.Load().decode(r.JSON)
+}
+
+// ByIPAsOf returns the hardware with the given ip address as it was at t.
+// Only the hardware having the address in one of its kept revisions is searched, if several hardware had the address
+// the one stored most recently before t is returned.
+func (h *Hardware) ByIPAsOf(v string, t time.Time) (string, error) {
+	ip, ok := netaddr.FromStdIP(net.ParseIP(v))
+	if !ok {
+		return "", errors.New("failed to parse ip")
+	}
+
+	return h.searchAsOf(t, func() map[id]int { return h.pastIPs[ip] }, func(r *Revision) bool {
+		_, ok := r.ips[ip]
+
+		return ok
+	})
+}
+
+// ByMACAsOf returns the hardware with the given mac address as it was at t, searched like ByIPAsOf.
+func (h *Hardware) ByMACAsOf(v string, t time.Time) (string, error) {
+	m, err := net.ParseMAC(strings.TrimSpace(strings.ToLower(v)))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse mac")
+	}
+
+	return h.searchAsOf(t, func() map[id]int { return h.pastMACs[mac(m.String())] }, func(r *Revision) bool {
+		_, ok := r.macs[mac(m.String())]
+
+		return ok
+	})
+}
+
+// searchAsOf returns the most recently stored of the revisions at t of the candidates matching, only decoding that one.
+// candidates is called with the lock held.
+func (h *Hardware) searchAsOf(t time.Time, candidates func() map[id]int, match func(*Revision) bool) (string, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var found Revision
+
+	for v := range candidates() {
+		r, ok := h.asOf(v, t)
+		if !ok || r.Deleted || !r.Time.After(found.Time) || !match(&r) {
+			continue
+		}
+
+		found = r
+	}
+
+	return h.codec.Load().decode(found.JSON)
+}
@@ -0,0 +1,178 @@
+package hardware
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"inet.af/netaddr"
+)
+
+func TestHistory(t *testing.T) {
+	assert := require.New(t)
+
+	const (
+		id1 = "00000000-0000-0000-0000-000000000001"
+		id2 = "00000000-0000-0000-0000-000000000002"
+	)
+
+	hw := New(Revisions(3), Tombstones(time.Hour))
+
+	j1 := conflictHW(id1, "10.0.0.1", "00:00:00:00:00:01")
+	_, err := hw.Add(j1)
+	assert.NoError(err)
+
+	j2 := conflictHW(id1, "10.0.0.2", "00:00:00:00:00:02")
+	_, _, err = hw.Swap(j2, FromPush)
+	assert.NoError(err)
+
+	revs := hw.History(id1)
+	assert.Len(revs, 2)
+	assert.Equal(j1, revs[0].JSON)
+	assert.Equal(FromIngest, revs[0].Source)
+	assert.Equal(j2, revs[1].JSON)
+	assert.Equal(FromPush, revs[1].Source)
+
+	// as of the first revision
+	at := revs[0].Time
+
+	j, err := hw.ByIDAsOf(id1, at)
+	assert.NoError(err)
+	assert.Equal(j1, j)
+
+	j, err = hw.ByIPAsOf("10.0.0.1", at)
+	assert.NoError(err)
+	assert.Equal(j1, j)
+
+	j, err = hw.ByMACAsOf("00:00:00:00:00:02", at)
+	assert.NoError(err)
+	assert.Empty(j)
+
+	j, err = hw.ByIDAsOf(id1, at.Add(-time.Nanosecond))
+	assert.NoError(err)
+	assert.Empty(j)
+
+	// the address moved to another hardware later on
+	j3 := conflictHW(id2, "10.0.0.1", "00:00:00:00:00:01")
+	_, err = hw.Add(j3)
+	assert.NoError(err)
+
+	j, err = hw.ByIPAsOf("10.0.0.1", at)
+	assert.NoError(err)
+	assert.Equal(j1, j)
+
+	j, err = hw.ByIPAsOf("10.0.0.1", time.Now())
+	assert.NoError(err)
+	assert.Equal(j3, j)
+
+	j, err = hw.ByMACAsOf("00-00-00-00-00-01", time.Now())
+	assert.NoError(err)
+	assert.Equal(j3, j)
+
+	// only the most recent revisions are kept
+	deleted := `{"id":"` + id1 + `","state":"deleted"}`
+	_, err = hw.Add(j1)
+	assert.NoError(err)
+
+	_, err = hw.Add(deleted)
+	assert.NoError(err)
+
+	revs = hw.History(id1)
+	assert.Len(revs, 3)
+	assert.Equal(j2, revs[0].JSON)
+	assert.True(revs[2].Deleted)
+
+	j, err = hw.ByIDAsOf(id1, time.Now())
+	assert.NoError(err)
+	assert.Empty(j)
+
+	_, err = hw.ByIPAsOf("not-an-ip", time.Now())
+	assert.Error(err)
+
+	// the addresses of the kept revisions are indexed, the deletion having none
+	ip1, ip2 := netaddr.MustParseIP("10.0.0.1"), netaddr.MustParseIP("10.0.0.2")
+	assert.Equal(map[netaddr.IP]map[id]int{ip1: {id1: 1, id2: 1}, ip2: {id1: 1}}, hw.pastIPs)
+
+	// and no longer once forgotten
+	hw = New(Revisions(3))
+	_, err = hw.Add(j1)
+	assert.NoError(err)
+	assert.Equal(map[mac]map[id]int{"00:00:00:00:00:01": {id1: 1}}, hw.pastMACs)
+
+	_, err = hw.Add(deleted)
+	assert.NoError(err)
+	assert.Empty(hw.pastIPs)
+	assert.Empty(hw.pastMACs)
+}
+
+func TestCompareAndSwap(t *testing.T) {
+	assert := require.New(t)
+
+	const id1 = "00000000-0000-0000-0000-000000000001"
+
+	hw := New(Revisions(5))
+
+	j1 := conflictHW(id1, "10.0.0.1", "00:00:00:00:00:01")
+	_, err := hw.CompareAndSwap(j1, FromPush, 1)
+	assert.ErrorIs(err, ErrRevisionMismatch)
+
+	_, err = hw.Add(j1)
+	assert.NoError(err)
+
+	j2 := conflictHW(id1, "10.0.0.2", "00:00:00:00:00:01")
+	w, err := hw.CompareAndSwap(j2, FromPush, 1)
+	assert.NoError(err)
+	assert.Equal(Written{ID: id1, Old: j1, Revision: 2, Seq: 1}, w)
+
+	// a writer still at the first revision loses
+	j3 := conflictHW(id1, "10.0.0.3", "00:00:00:00:00:01")
+	_, err = hw.CompareAndSwap(j3, FromPush, 1)
+	assert.ErrorIs(err, ErrRevisionMismatch)
+
+	j, rev, _, err := hw.ByIDStatus(id1)
+	assert.NoError(err)
+	assert.Equal(j2, j)
+	assert.Equal(uint64(2), rev)
+
+	j, err = hw.ByIP("10.0.0.3")
+	assert.NoError(err)
+	assert.Empty(j)
+
+	// 0 skips the check
+	_, err = hw.CompareAndSwap(j3, FromPush, 0)
+	assert.NoError(err)
+
+	revs := hw.History(id1)
+	assert.Len(revs, 3)
+	for i, r := range revs {
+		assert.Equal(uint64(i+1), r.Number)
+	}
+
+	// hardware forgotten once deleted doesn't start over, a writer still at an old revision can't tell
+	_, err = hw.Add(`{"id":"` + id1 + `","state":"deleted"}`)
+	assert.NoError(err)
+
+	_, err = hw.CompareAndSwap(j1, FromPush, 3)
+	assert.ErrorIs(err, ErrRevisionMismatch)
+
+	_, err = hw.Add(j1)
+	assert.NoError(err)
+
+	w, err = hw.CompareAndSwap(j2, FromPush, 5)
+	assert.NoError(err)
+	assert.Equal(uint64(6), w.Revision)
+	assert.Empty(hw.lastRevs)
+
+	// nor once its tombstone is swept
+	hw = New(Tombstones(time.Minute))
+	_, err = hw.Add(j1)
+	assert.NoError(err)
+
+	_, err = hw.Add(`{"id":"` + id1 + `","state":"deleted"}`)
+	assert.NoError(err)
+	assert.Equal(1, hw.Sweep(time.Now().Add(time.Hour)))
+
+	w, err = hw.CompareAndSwap(j1, FromPush, 0)
+	assert.NoError(err)
+	assert.Equal(uint64(3), w.Revision)
+}
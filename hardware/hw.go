@@ -1,10 +1,15 @@
 package hardware
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"encoding/json"
 	"net"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/packethost/pkg/log"
@@ -18,18 +23,103 @@ type (
 	mac string
 )
 
+// switchPort is a port of a switch, by the switch's hostname (lower cased) and the port's name.
+type switchPort struct {
+	sw   string
+	port string
+}
+
+// portLink is a hardware port connected to a switch port.
+type portLink struct {
+	id   id
+	port string
+}
+
 // Hardware is the interface to the in memory DB of hardware objects.
 type Hardware struct {
 	gauge  prometheus.Gauge
 	logger *log.Logger
 	mu     sync.RWMutex
 	hw     map[id]struct {
-		j    string
-		ips  map[netaddr.IP]bool
-		macs map[mac]bool
+		j     string // compressed with codec if configured with Compression
+		size  int    // of the uncompressed document
+		state string
+		ips   map[netaddr.IP]IPMatch
+		macs  map[mac]string // port names
+		ports map[switchPort]bool
+		vals  map[string]map[string]bool // secondary index values
+		rev   uint64
+		sum   [sha256.Size]byte // of the canonical document
 	}
-	byIP  map[netaddr.IP]id
-	byMAC map[mac]id
+	byIP   map[netaddr.IP]id
+	byMAC  map[mac]id
+	byPort map[switchPort]portLink
+	ids    []id         // sorted
+	ips    []netaddr.IP // sorted keys of byIP
+
+	indexes map[string]*index
+
+	policy        ConflictPolicy
+	claims        map[claim]map[id]bool // hardware having each mac and ip address
+	conflictGauge *prometheus.GaugeVec
+
+	ttl            time.Duration
+	tombstones     map[id]time.Time // when each deleted hardware was deleted
+	tombstoneGauge prometheus.Gauge
+	// the revision of each hardware deleted and forgotten, which it picks up from if stored again so a revision is never
+	// reused for an id
+	lastRevs map[id]uint64
+
+	revisions int
+	history   map[id][]Revision // oldest first
+	pushes    uint64            // documents stored from FromPush, see Written.Seq
+	// the ids of the hardware having each address in its kept revisions, with how many of them have it
+	pastIPs  map[netaddr.IP]map[id]int
+	pastMACs map[mac]map[id]int
+
+	feed Feed
+
+	validator *Validator
+
+	graph              StateGraph
+	transitions        TransitionPolicy
+	illegalTransitions *prometheus.CounterVec
+
+	compress           bool
+	dict               []byte
+	compressionSeconds *prometheus.CounterVec
+	codec              atomic.Pointer[codec] // nil unless configured with Compression
+
+	// sizes of the documents kept, as they are and as stored, see countBytes
+	rawBytes    atomic.Int64
+	storedBytes atomic.Int64
+
+	// the lookups by id, ip and mac address and switch port are served from snap, published from view by publish
+	view      view
+	seq       uint64 // writes stored, with mu held
+	snap      atomic.Pointer[snapshot]
+	pubMu     sync.Mutex
+	published uint64 // writes in snap, with pubMu held
+}
+
+// Sources of the addresses in IPMatch.
+const (
+	SourceIPAddresses         = "ip_addresses"
+	SourceInstanceIPAddresses = "instance.ip_addresses"
+)
+
+// IPMatch describes the address looked up with ByIPMatch as it appears in its hardware.
+type IPMatch struct {
+	Family     int // 4 or 6
+	Management bool
+	Public     bool
+	Source     string // SourceIPAddresses or SourceInstanceIPAddresses
+}
+
+// CIDRMatch is a hardware with addresses in a prefix looked up with ByCIDR.
+type CIDRMatch struct {
+	ID  string
+	IPs []string
 }
 
 type hardware struct {
@@ -37,16 +127,27 @@ type hardware struct {
 	State    string
 	Instance struct {
 		IPs []struct {
-			Address string
+			Address    string
+			Management bool
+			Public     bool
 		} `json:"ip_addresses"`
 	}
 	IPs []struct {
-		Address string
+		Address    string
+		Management bool
+		Public     bool
 	} `json:"ip_addresses"`
 	Ports []struct {
+		Name string
 		Data struct {
 			MAC string
 		}
+		ConnectedPorts []struct {
+			Name     string
+			Hardware struct {
+				Hostname string
+			}
+		} `json:"connected_ports"`
 	} `json:"network_ports"`
 }
 
@@ -58,49 +159,187 @@ type Option func(*Hardware)
 func New(options ...Option) *Hardware {
 	h := &Hardware{
 		hw: map[id]struct {
-			j    string
-			ips  map[netaddr.IP]bool
-			macs map[mac]bool
+			j     string // compressed with codec if configured with Compression
+			size  int    // of the uncompressed document
+			state string
+			ips   map[netaddr.IP]IPMatch
+			macs  map[mac]string // port names
+			ports map[switchPort]bool
+			vals  map[string]map[string]bool // secondary index values
+			rev   uint64
+			sum   [sha256.Size]byte // of the canonical document
 		}{},
-		byIP:  map[netaddr.IP]id{},
-		byMAC: map[mac]id{},
+		byIP:    map[netaddr.IP]id{},
+		byMAC:   map[mac]id{},
+		byPort:  map[switchPort]portLink{},
+		indexes: map[string]*index{},
+		policy:  LastWriteWins,
+		claims:  map[claim]map[id]bool{},
+
+		transitions: TransitionsAllow,
+
+		tombstones: map[id]time.Time{},
+		lastRevs:   map[id]uint64{},
+		history:    map[id][]Revision{},
+		pastIPs:    map[netaddr.IP]map[id]int{},
+		pastMACs:   map[mac]map[id]int{},
+
+		view: newView(),
 	}
 
 	for _, opt := range options {
 		opt(h)
 	}
 
+	if h.compress {
+		h.codec.Store(h.newCodec(h.dict))
+	}
+
+	h.snap.Store(&snapshot{
+		codec:  h.codec.Load(),
+		hw:     h.view.hw.pub,
+		byIP:   h.view.byIP.pub,
+		byMAC:  h.view.byMAC.pub,
+		byPort: h.view.byPort.pub,
+	})
+
 	return h
 }
 
 // Add inserts a new hardware object into the database, overriding any pre-existing values.
-// If state == deleted Add will delete the the object from the db.
+// If state == deleted Add will delete the the object from the db, leaving a tombstone if configured with Tombstones.
 // API currently has a bug where it sends invalid ip_address objects where the address (and others) is missing, we log this case (if logger is configured) and continue processing.
+// Revisions are recorded as coming from ingestion.
 func (h *Hardware) Add(j string) (string, error) {
+	id, _, err := h.Swap(j, FromIngest)
+
+	return id, err
+}
+
+// AddBulk implements BulkAdder, storing the documents one at a time, as coming from ingestion, under a single lock.
+func (h *Hardware) AddBulk(js []string) ([]error, error) {
+	errs := make([]error, len(js))
+	docs := make([]*doc, len(js))
+
+	for i, j := range js {
+		docs[i], errs[i] = h.parse(j)
+	}
+
+	h.mu.Lock()
+	for i, d := range docs {
+		if d != nil {
+			_, errs[i] = h.store(d, FromIngest, 0)
+		}
+	}
+	seq := h.seq
+	h.mu.Unlock()
+
+	h.publish(seq)
+
+	return errs, nil
+}
+
+// Swap behaves like Add but also returns the document previously stored for the id, or "" if there was none.
+// Addresses already belonging to another hardware are resolved according to the conflict policy.
+// The revision is recorded as coming from source, FromIngest or FromPush.
+func (h *Hardware) Swap(j, source string) (string, string, error) {
+	w, err := h.CompareAndSwap(j, source, 0)
+
+	return w.ID, w.Old, err
+}
+
+// CompareAndSwap behaves like Swap but fails with ErrRevisionMismatch, without storing j, unless the revision stored
+// for the id is rev. A rev of 0 skips the check.
+// Documents semantically identical to the stored one, as in equal once canonicalized, are not stored again.
+func (h *Hardware) CompareAndSwap(j, source string, rev uint64) (Written, error) {
+	d, err := h.parse(j)
+	if err != nil {
+		return Written{}, err
+	}
+
+	h.mu.Lock()
+	w, err := h.store(d, source, rev)
+	seq := h.seq
+	h.mu.Unlock()
+
+	h.publish(seq)
+
+	if err == nil {
+		h.notify([]Written{w}, []string{j})
+	}
+
+	return w, err
+}
+
+// Written describes a document stored by CompareAndSwap, Batch or Patch.
+type Written struct {
+	ID       string
+	Old      string // the document it replaced, "" if none
+	Revision uint64
+	// Unchanged is set if the document was identical to the stored one, and so was not stored again
+	Unchanged bool
+	// Seq numbers the documents stored from FromPush in the order they were stored, from 1, and is 0 for the others
+	Seq uint64
+}
+
+// doc is a hardware document parsed for storing.
+type doc struct {
+	j      string
+	stored string // j compressed with codec
+	codec  *codec
+	hw     hardware
+	ips    map[netaddr.IP]IPMatch
+	macs   map[mac]string // port names
+	vals   map[string]map[string]bool
+	sum    [sha256.Size]byte
+}
+
+func (d *doc) id() id {
+	return id(d.hw.ID)
+}
+
+// ErrInvalidDocument is matched by the errors returned for documents that can't be decoded, or whose id or addresses
+// can't be parsed.
+var ErrInvalidDocument = errors.New("invalid hardware document")
+
+// invalidError is an error of a document itself, matching ErrInvalidDocument while keeping its message.
+type invalidError struct {
+	error
+}
+
+func (e invalidError) Is(target error) bool {
+	return target == ErrInvalidDocument
+}
+
+func (e invalidError) Unwrap() error {
+	return e.error
+}
+
+// parse decodes j and its addresses and index values.
+// It doesn't need the lock so a bad document doesn't leave the indexes half updated.
+func (h *Hardware) parse(j string) (*doc, error) {
+	// before decoding, so values of the wrong type are reported with their path
+	if err := h.validator.Validate(j); err != nil {
+		return nil, err
+	}
+
 	hw := hardware{}
 
 	err := json.Unmarshal([]byte(j), &hw)
 	if err != nil {
-		return "", errors.Wrap(err, "unable to decode json")
+		return nil, invalidError{errors.Wrap(err, "unable to decode json")}
 	}
 
 	if _, err = uuid.Parse(hw.ID); err != nil {
-		return "", errors.Wrap(err, "not a valid uuid for id")
+		return nil, invalidError{errors.Wrap(err, "not a valid uuid for id")}
 	}
 
-	h.mu.Lock()
-	defer h.mu.Unlock()
-
-	id := id(hw.ID)
-	og, ok := h.hw[id]
-	ng := h.hw[id]
-	ng.j = j
-	ng.ips = map[netaddr.IP]bool{}
-	ng.macs = map[mac]bool{}
-
-	change := 1
-	if ok {
-		change = 0
+	var vals map[string]map[string]bool
+	if hw.State != "deleted" {
+		vals, err = h.indexValues(j)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	if hw.State == "deleted" {
@@ -109,6 +348,8 @@ func (h *Hardware) Add(j string) (string, error) {
 		hw.Ports = nil
 	}
 
+	ips := map[netaddr.IP]IPMatch{}
+
 	for _, ip := range hw.IPs {
 		if ip.Address == "" {
 			if h.logger != nil {
@@ -120,15 +361,10 @@ func (h *Hardware) Add(j string) (string, error) {
 
 		nIP, ok := netaddr.FromStdIP(net.ParseIP(ip.Address))
 		if !ok {
-			return "", errors.New("failed to parse ip")
-		}
-
-		if _, ok := og.ips[nIP]; ok {
-			og.ips[nIP] = false
+			return nil, invalidError{errors.New("failed to parse ip")}
 		}
 
-		ng.ips[nIP] = true
-		h.byIP[nIP] = id
+		ips[nIP] = IPMatch{Family: family(nIP), Management: ip.Management, Public: ip.Public, Source: SourceIPAddresses}
 	}
 
 	for _, ip := range hw.Instance.IPs {
@@ -142,24 +378,13 @@ func (h *Hardware) Add(j string) (string, error) {
 
 		nIP, ok := netaddr.FromStdIP(net.ParseIP(ip.Address))
 		if !ok {
-			return "", errors.New("failed to parse ip")
-		}
-
-		if _, ok := og.ips[nIP]; ok {
-			og.ips[nIP] = false
+			return nil, invalidError{errors.New("failed to parse ip")}
 		}
 
-		ng.ips[nIP] = true
-		h.byIP[nIP] = id
+		ips[nIP] = IPMatch{Family: family(nIP), Management: ip.Management, Public: ip.Public, Source: SourceInstanceIPAddresses}
 	}
 
-	for ip, del := range og.ips {
-		if del {
-			if h.byIP[ip] == id {
-				delete(h.byIP, ip)
-			}
-		}
-	}
+	macs := map[mac]string{}
 
 	for _, port := range hw.Ports {
 		if port.Data.MAC == "" {
@@ -172,31 +397,235 @@ func (h *Hardware) Add(j string) (string, error) {
 
 		m, err := net.ParseMAC(port.Data.MAC)
 		if err != nil {
-			return "", errors.Wrap(err, "failed to parse mac")
+			return nil, invalidError{errors.Wrap(err, "failed to parse mac")}
+		}
+
+		macs[mac(m.String())] = port.Name
+	}
+
+	sum, err := canonicalSum(j)
+	if err != nil {
+		return nil, err
+	}
+
+	c := h.codec.Load()
+
+	stored, err := c.encode(j)
+	if err != nil {
+		return nil, err
+	}
+
+	return &doc{j: j, stored: stored, codec: c, hw: hw, ips: ips, macs: macs, vals: vals, sum: sum}, nil
+}
+
+// canonicalSum hashes j re-encoded with sorted keys and without insignificant whitespace.
+// Numbers are kept as written, so 1 and 1.0 differ.
+func canonicalSum(j string) ([sha256.Size]byte, error) {
+	dec := json.NewDecoder(strings.NewReader(j))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return [sha256.Size]byte{}, errors.Wrap(err, "unable to decode json")
+	}
+
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+
+	if err := enc.Encode(v); err != nil {
+		return [sha256.Size]byte{}, errors.Wrap(err, "unable to encode json")
+	}
+
+	return sha256.Sum256(buf.Bytes()), nil
+}
+
+// store stores d, checking the revision first unless rev is 0, unless it is identical to the stored document.
+// Must be called with the lock held.
+func (h *Hardware) store(d *doc, source string, rev uint64) (Written, error) {
+	j, hw, ips, macs, vals := d.j, d.hw, d.ips, d.macs, d.vals
+
+	id := id(hw.ID)
+
+	if rev != 0 && h.hw[id].rev != rev {
+		return Written{}, errors.Wrapf(ErrRevisionMismatch, "%s is at revision %d, not %d", id, h.hw[id].rev, rev)
+	}
+
+	og, ok := h.hw[id]
+
+	old, err := h.codec.Load().decode(og.j)
+	if err != nil {
+		return Written{}, err
+	}
+
+	if ok && og.sum == d.sum {
+		return Written{ID: string(id), Old: old, Revision: og.rev, Unchanged: true}, nil
+	}
+
+	// the compression dictionary changed since d was parsed
+	if c := h.codec.Load(); d.codec != c {
+		d.stored, err = c.encode(j)
+		if err != nil {
+			return Written{}, err
 		}
 
-		mac := mac(m.String())
-		if _, ok := og.macs[mac]; ok {
-			og.macs[mac] = false
+		d.codec = c
+	}
+
+	if err := h.checkPushedState(d, source); err != nil {
+		return Written{}, err
+	}
+
+	if h.policy == Reject {
+		if err := h.checkConflicts(id, ips, macs); err != nil {
+			return Written{}, err
 		}
+	}
+
+	ng := h.hw[id]
+	ng.j = d.stored
+	ng.size = len(j)
+	ng.state = hw.State
+	ng.ips = ips
+	ng.macs = macs
+	ng.ports = map[switchPort]bool{}
+	ng.rev = og.rev + 1
+	if !ok {
+		ng.rev = h.lastRevs[id] + 1
+	}
+	ng.sum = d.sum
 
-		ng.macs[mac] = true
-		h.byMAC[mac] = id
+	_, dead := h.tombstones[id]
+	live := ok && !dead
+
+	change := 1
+	if live {
+		change = 0
+	}
+
+	for ip := range ips {
+		h.addClaim(claim{kind: ConflictIP, value: ip.String()}, id)
+
+		if owner, ok := h.byIP[ip]; h.wins(owner, ok, id) {
+			h.setIP(ip, id)
+		}
 	}
 
-	for mac, del := range og.macs {
+	for ip := range og.ips {
+		if _, ok := ips[ip]; ok {
+			continue
+		}
+
+		next := h.removeClaim(claim{kind: ConflictIP, value: ip.String()}, id)
+		if h.byIP[ip] != id {
+			continue
+		}
+
+		if next != "" {
+			h.setIP(ip, next)
+		} else {
+			h.deleteIP(ip)
+		}
+	}
+
+	for mac := range macs {
+		h.addClaim(claim{kind: ConflictMAC, value: string(mac)}, id)
+
+		if owner, ok := h.byMAC[mac]; h.wins(owner, ok, id) {
+			h.setMAC(mac, id)
+		}
+	}
+
+	for mac := range og.macs {
+		if _, ok := macs[mac]; ok {
+			continue
+		}
+
+		next := h.removeClaim(claim{kind: ConflictMAC, value: string(mac)}, id)
+		if h.byMAC[mac] != id {
+			continue
+		}
+
+		if next != "" {
+			h.setMAC(mac, next)
+		} else {
+			h.deleteMAC(mac)
+		}
+	}
+
+	for _, port := range hw.Ports {
+		for _, cp := range port.ConnectedPorts {
+			if cp.Name == "" || cp.Hardware.Hostname == "" {
+				continue
+			}
+
+			sp := switchPort{sw: strings.ToLower(cp.Hardware.Hostname), port: cp.Name}
+			if _, ok := og.ports[sp]; ok {
+				og.ports[sp] = false
+			}
+
+			ng.ports[sp] = true
+			h.setPort(sp, portLink{id: id, port: port.Name})
+		}
+	}
+
+	for sp, del := range og.ports {
 		if del {
-			if h.byMAC[mac] == id {
-				delete(h.byMAC, mac)
+			if h.byPort[sp].id == id {
+				h.deletePort(sp)
 			}
 		}
 	}
 
-	if hw.State != "deleted" {
+	h.updateIndexes(id, og.vals, vals)
+	ng.vals = vals
+
+	h.touch(id)
+
+	now := time.Now()
+	h.record(id, Revision{
+		Number:  ng.rev,
+		JSON:    d.stored,
+		Time:    now,
+		Source:  source,
+		Deleted: hw.State == "deleted",
+		size:    len(j),
+		ips:     ips,
+		macs:    macs,
+	})
+
+	if ok {
+		h.countBytes(og.j, og.size, -1)
+	}
+
+	switch {
+	case hw.State != "deleted":
 		h.hw[id] = ng
-	} else {
-		change = -1
+		if !ok {
+			h.insertID(id)
+		}
+		h.revive(id)
+		h.countBytes(ng.j, ng.size, 1)
+		delete(h.lastRevs, id)
+	case h.ttl > 0:
+		change = 0
+		if live {
+			change = -1
+		}
+		h.bury(id, d, ng.rev, now)
+		h.countBytes(d.stored, len(j), 1)
+		delete(h.lastRevs, id)
+	default:
+		change = 0
+		if live {
+			change = -1
+		}
 		delete(h.hw, id)
+		h.forget(id)
+		h.lastRevs[id] = ng.rev
+		if ok {
+			h.removeID(id)
+		}
 	}
 
 	if h.gauge != nil {
@@ -207,66 +636,251 @@ func (h *Hardware) Add(j string) (string, error) {
 		}
 	}
 
-	return string(id), nil
+	w := Written{ID: string(id), Old: old, Revision: ng.rev}
+	if source == FromPush {
+		h.pushes++
+		w.Seq = h.pushes
+	}
+
+	return w, nil
+}
+
+func family(ip netaddr.IP) int {
+	if ip.Is4() {
+		return 4
+	}
+
+	return 6
+}
+
+func (h *Hardware) insertID(v id) {
+	i := sort.Search(len(h.ids), func(i int) bool { return h.ids[i] >= v })
+	h.ids = append(h.ids, "")
+	copy(h.ids[i+1:], h.ids[i:])
+	h.ids[i] = v
+}
+
+func (h *Hardware) removeID(v id) {
+	i := sort.Search(len(h.ids), func(i int) bool { return h.ids[i] >= v })
+	if i < len(h.ids) && h.ids[i] == v {
+		h.ids = append(h.ids[:i], h.ids[i+1:]...)
+	}
+}
+
+func (h *Hardware) setIP(ip netaddr.IP, v id) {
+	if _, ok := h.byIP[ip]; !ok {
+		i := sort.Search(len(h.ips), func(i int) bool { return !h.ips[i].Less(ip) })
+		h.ips = append(h.ips, netaddr.IP{})
+		copy(h.ips[i+1:], h.ips[i:])
+		h.ips[i] = ip
+	}
+
+	h.byIP[ip] = v
+	h.view.ips[ip] = true
+}
+
+func (h *Hardware) setMAC(m mac, v id) {
+	h.byMAC[m] = v
+	h.view.macs[m] = true
+}
+
+func (h *Hardware) deleteMAC(m mac) {
+	delete(h.byMAC, m)
+	h.view.macs[m] = true
+}
+
+func (h *Hardware) setPort(sp switchPort, l portLink) {
+	h.byPort[sp] = l
+	h.view.ports[sp] = true
 }
 
-// All returns each entry stored in memory.
+func (h *Hardware) deletePort(sp switchPort) {
+	delete(h.byPort, sp)
+	h.view.ports[sp] = true
+}
+
+func (h *Hardware) deleteIP(ip netaddr.IP) {
+	delete(h.byIP, ip)
+	h.view.ips[ip] = true
+
+	i := sort.Search(len(h.ips), func(i int) bool { return !h.ips[i].Less(ip) })
+	if i < len(h.ips) && h.ips[i] == ip {
+		h.ips = append(h.ips[:i], h.ips[i+1:]...)
+	}
+}
+
+// All returns each entry stored in memory, ordered by id, tombstones excluded.
 func (h *Hardware) All(fn func(string) error) error {
-	hw := map[id]string{}
+	_, _, err := h.Page("", 0, false, fn)
+
+	return err
+}
+
+// Page calls fn, in id order, for up to limit entries (all if limit < 1) whose id sorts after the given id,
+// "" starts at the first entry. Tombstones are only included if deleted is true.
+// It returns the id of the last entry passed to fn and whether more entries follow it.
+// Only the page's entries are copied while holding the lock, fn is called without it.
+func (h *Hardware) Page(after string, limit int, deleted bool, fn func(string) error) (string, bool, error) {
+	after = strings.TrimSpace(strings.ToLower(after))
 
 	h.mu.RLock()
 
-	for k, v := range h.hw {
-		hw[k] = v.j
+	start := 0
+	if after != "" {
+		start = sort.Search(len(h.ids), func(i int) bool { return h.ids[i] > id(after) })
+	}
+
+	js := []string{}
+	last := ""
+	more := false
+
+	for _, k := range h.ids[start:] {
+		if _, dead := h.tombstones[k]; dead && !deleted {
+			continue
+		}
+
+		if limit > 0 && len(js) == limit {
+			more = true
+
+			break
+		}
+
+		js = append(js, h.hw[k].j)
+		last = string(k)
 	}
+	c := h.codec.Load()
 	h.mu.RUnlock()
 
-	for _, v := range hw {
-		err := fn(v)
+	for _, j := range js {
+		j, err := c.decode(j)
+		if err != nil {
+			return "", false, err
+		}
+
+		err = fn(j)
 		if err != nil {
-			return errors.Wrap(err, "callback function returned an error")
+			return "", false, errors.Wrap(err, "callback function returned an error")
 		}
 	}
 
-	return nil
+	return last, more, nil
 }
 
-// ByID returns the hardware with the given id.
+// ByID returns the hardware with the given id, "" if it was deleted.
 func (h *Hardware) ByID(v string) (string, error) {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
-
-	v = strings.TrimSpace(strings.ToLower(v))
+	j, _, deleted, err := h.ByIDStatus(v)
+	if !deleted.IsZero() {
+		return "", err
+	}
 
-	return h.hw[id(v)].j, nil
+	return j, err
 }
 
 // ByID returns the hardware with the given ip address.
 func (h *Hardware) ByIP(v string) (string, error) {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
+	j, _, _, err := h.ByIPMatch(v)
+
+	return j, err
+}
 
+// ByIPMatch returns the hardware with the given ip address along with its revision and how the address appears in it.
+func (h *Hardware) ByIPMatch(v string) (string, uint64, IPMatch, error) {
 	ip, ok := netaddr.FromStdIP(net.ParseIP(v))
 	if !ok {
-		return "", errors.New("failed to parse ip")
+		return "", 0, IPMatch{}, errors.New("failed to parse ip")
 	}
 
-	return h.hw[h.byIP[ip]].j, nil
+	s := h.snap.Load()
+	owner, _ := s.byIP.get(hashIP(ip), ip)
+	hw, _ := s.hw.get(hashID(owner), owner)
+
+	j, err := s.codec.decode(hw.j)
+
+	return j, hw.rev, hw.ips[ip], err
 }
 
-// ByID returns the hardware with the given mac address.
-func (h *Hardware) ByMAC(v string) (string, error) {
+// ByCIDR returns the hardware with ip addresses in the given prefix, e.g. 10.1.4.0/23, along with the addresses that
+// matched, ordered by id.
+func (h *Hardware) ByCIDR(v string) ([]CIDRMatch, error) {
+	p, err := netaddr.ParseIPPrefix(strings.TrimSpace(v))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse cidr")
+	}
+
+	r := p.Range()
+
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
+	matches := map[id]*CIDRMatch{}
+	ids := []id{}
+
+	i := sort.Search(len(h.ips), func(i int) bool { return !h.ips[i].Less(r.From()) })
+	for ; i < len(h.ips) && !r.To().Less(h.ips[i]); i++ {
+		ip := h.ips[i]
+		id := h.byIP[ip]
+
+		m, ok := matches[id]
+		if !ok {
+			m = &CIDRMatch{ID: string(id)}
+			matches[id] = m
+			ids = append(ids, id)
+		}
+
+		m.IPs = append(m.IPs, ip.String())
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	ms := make([]CIDRMatch, 0, len(ids))
+	for _, id := range ids {
+		ms = append(ms, *matches[id])
+	}
+
+	return ms, nil
+}
+
+// ByID returns the hardware with the given mac address.
+func (h *Hardware) ByMAC(v string) (string, error) {
+	j, _, _, err := h.ByMACPort(v)
+
+	return j, err
+}
+
+// ByMACPort returns the hardware with the given mac address along with its revision and the name of the port with the
+// address.
+func (h *Hardware) ByMACPort(v string) (string, uint64, string, error) {
 	m, err := net.ParseMAC(strings.TrimSpace(strings.ToLower(v)))
 	if err != nil {
-		return "", errors.Wrap(err, "failed to parse mac")
+		return "", 0, "", errors.Wrap(err, "failed to parse mac")
 	}
 
-	id := h.byMAC[mac(m.String())]
+	k := mac(m.String())
+	s := h.snap.Load()
+	owner, _ := s.byMAC.get(hashMAC(k), k)
+	hw, _ := s.hw.get(hashID(owner), owner)
+
+	j, err := s.codec.decode(hw.j)
+
+	return j, hw.rev, hw.macs[k], err
+}
+
+// ByPort returns the hardware connected to the given port of a switch, by the switch's hostname and the port's name,
+// e.g. leaf-3 xe-0/0/12, along with the name of the hardware's port, e.g. eth0.
+func (h *Hardware) ByPort(sw, port string) (string, string, error) {
+	sp := switchPort{sw: strings.TrimSpace(strings.ToLower(sw)), port: strings.TrimSpace(port)}
+	s := h.snap.Load()
+
+	l, ok := s.byPort.get(hashSwitchPort(sp), sp)
+	if !ok {
+		return "", "", nil
+	}
+
+	hw, _ := s.hw.get(hashID(l.id), l.id)
+
+	j, err := s.codec.decode(hw.j)
 
-	return h.hw[id].j, nil
+	return j, l.port, err
 }
 
 // Gauge will set the gauge used to track db size metric.
@@ -2,6 +2,7 @@ package hardware
 
 import (
 	"fmt"
+	"sort"
 	"testing"
 
 	"github.com/google/uuid"
@@ -216,6 +217,64 @@ func TestAll(t *testing.T) {
 	}), "callback function returned an error: "+errstr)
 }
 
+func TestPage(t *testing.T) {
+	assert := require.New(t)
+
+	hw := New()
+
+	ids := []string{}
+	for i := 0; i < 5; i++ {
+		id, err := hw.Add(`{"id": "` + uuid.New().String() + `"}`)
+		assert.NoError(err)
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var got []string
+	collect := func(j string) error {
+		got = append(got, j)
+
+		return nil
+	}
+
+	last, more, err := hw.Page("", 2, false, collect)
+	assert.NoError(err)
+	assert.True(more)
+	assert.Equal(ids[1], last)
+
+	last, more, err = hw.Page(last, 2, false, collect)
+	assert.NoError(err)
+	assert.True(more)
+	assert.Equal(ids[3], last)
+
+	last, more, err = hw.Page(last, 2, false, collect)
+	assert.NoError(err)
+	assert.False(more)
+	assert.Equal(ids[4], last)
+
+	assert.Len(got, len(ids))
+	for i, j := range got {
+		assert.Contains(j, ids[i])
+	}
+
+	// a deleted id no longer shows up, but can still be resumed from
+	_, err = hw.Add(`{"id": "` + ids[1] + `", "state": "deleted"}`)
+	assert.NoError(err)
+
+	got = nil
+	last, more, err = hw.Page(ids[1], 0, false, collect)
+	assert.NoError(err)
+	assert.False(more)
+	assert.Equal(ids[4], last)
+	assert.Len(got, 3)
+	assert.Contains(got[0], ids[2])
+
+	got = nil
+	_, _, err = hw.Page(ids[4], 0, false, collect)
+	assert.NoError(err)
+	assert.Empty(got)
+}
+
 func TestByID(t *testing.T) {
 	assert := require.New(t)
 
@@ -232,6 +291,33 @@ func TestByID(t *testing.T) {
 	assert.Equal(j, h)
 }
 
+func TestSwap(t *testing.T) {
+	assert := require.New(t)
+
+	id := uuid.New().String()
+	j1 := `{"id":"` + id + `","state":"provisioning"}`
+	j2 := `{"id":"` + id + `","state":"in_use"}`
+
+	hw := New()
+
+	got, old, err := hw.Swap(j1, FromPush)
+	assert.NoError(err)
+	assert.Equal(id, got)
+	assert.Empty(old)
+
+	_, old, err = hw.Swap(j2, FromPush)
+	assert.NoError(err)
+	assert.Equal(j1, old)
+
+	_, old, err = hw.Swap(`{"id":"`+id+`","state":"deleted"}`, FromPush)
+	assert.NoError(err)
+	assert.Equal(j2, old)
+
+	_, old, err = hw.Swap(`{"id":"not-a-uuid"}`, FromPush)
+	assert.Error(err)
+	assert.Empty(old)
+}
+
 func TestByIP(t *testing.T) {
 	assert := require.New(t)
 
@@ -317,6 +403,167 @@ func TestByIP(t *testing.T) {
 	}
 }
 
+func TestByCIDR(t *testing.T) {
+	assert := require.New(t)
+
+	hw := New()
+
+	id1, id2, id3 := "00000000-0000-0000-0000-000000000001", "00000000-0000-0000-0000-000000000002", "00000000-0000-0000-0000-000000000003"
+	for _, j := range []string{
+		`{"id":"` + id2 + `","ip_addresses":[{"address":"10.1.5.255"}],"instance":{"ip_addresses":[{"address":"10.1.4.7"},{"address":"2604:1380::7"}]}}`,
+		`{"id":"` + id1 + `","ip_addresses":[{"address":"10.1.4.1"},{"address":"10.1.6.0"}]}`,
+		`{"id":"` + id3 + `","ip_addresses":[{"address":"10.1.3.255"}]}`,
+	} {
+		_, err := hw.Add(j)
+		assert.NoError(err)
+	}
+
+	ms, err := hw.ByCIDR("10.1.4.0/23")
+	assert.NoError(err)
+	assert.Equal([]CIDRMatch{
+		{ID: id1, IPs: []string{"10.1.4.1"}},
+		{ID: id2, IPs: []string{"10.1.4.7", "10.1.5.255"}},
+	}, ms)
+
+	// host bits are ignored
+	ms, err = hw.ByCIDR("10.1.4.9/23")
+	assert.NoError(err)
+	assert.Len(ms, 2)
+
+	ms, err = hw.ByCIDR("2604:1380::/32")
+	assert.NoError(err)
+	assert.Equal([]CIDRMatch{{ID: id2, IPs: []string{"2604:1380::7"}}}, ms)
+
+	ms, err = hw.ByCIDR("0.0.0.0/0")
+	assert.NoError(err)
+	assert.Len(ms, 3)
+
+	ms, err = hw.ByCIDR("192.168.0.0/16")
+	assert.NoError(err)
+	assert.Empty(ms)
+
+	// moved and removed addresses leave the index
+	_, err = hw.Add(`{"id":"` + id2 + `","ip_addresses":[{"address":"10.1.3.1"}]}`)
+	assert.NoError(err)
+	_, err = hw.Add(`{"id":"` + id1 + `","state":"deleted"}`)
+	assert.NoError(err)
+
+	ms, err = hw.ByCIDR("10.1.4.0/23")
+	assert.NoError(err)
+	assert.Empty(ms)
+
+	ms, err = hw.ByCIDR("10.1.0.0/16")
+	assert.NoError(err)
+	assert.Equal([]CIDRMatch{
+		{ID: id2, IPs: []string{"10.1.3.1"}},
+		{ID: id3, IPs: []string{"10.1.3.255"}},
+	}, ms)
+	assert.Len(hw.ips, len(hw.byIP))
+
+	_, err = hw.ByCIDR("10.1.4.0")
+	assert.Error(err)
+}
+
+func TestByPort(t *testing.T) {
+	assert := require.New(t)
+
+	hw := New()
+
+	id1, id2 := "00000000-0000-0000-0000-000000000001", "00000000-0000-0000-0000-000000000002"
+	hw1 := `{"id":"` + id1 + `","network_ports":[` +
+		`{"name":"eth0","connected_ports":[{"name":"xe-0/0/12","hardware":{"hostname":"leaf-3"}}]},` +
+		`{"name":"eth1","connected_ports":[{"name":"xe-0/0/12","hardware":{"hostname":"Leaf-4"}}]},` +
+		`{"name":"bond0"}]}`
+	_, err := hw.Add(hw1)
+	assert.NoError(err)
+
+	for _, test := range []struct {
+		sw, port string
+		j, name  string
+	}{
+		{sw: "leaf-3", port: "xe-0/0/12", j: hw1, name: "eth0"},
+		{sw: "LEAF-4", port: " xe-0/0/12", j: hw1, name: "eth1"},
+		{sw: "leaf-3", port: "xe-0/0/13"},
+		{sw: "leaf-5", port: "xe-0/0/12"},
+	} {
+		j, name, err := hw.ByPort(test.sw, test.port)
+		assert.NoError(err)
+		assert.Equal(test.j, j, "%s %s", test.sw, test.port)
+		assert.Equal(test.name, name, "%s %s", test.sw, test.port)
+	}
+
+	// recabling moves the switch port to the last hardware pushed with it
+	hw2 := `{"id":"` + id2 + `","network_ports":[{"name":"eth0","connected_ports":[{"name":"xe-0/0/12","hardware":{"hostname":"leaf-3"}}]}]}`
+	_, err = hw.Add(hw2)
+	assert.NoError(err)
+
+	j, name, err := hw.ByPort("leaf-3", "xe-0/0/12")
+	assert.NoError(err)
+	assert.Equal(hw2, j)
+	assert.Equal("eth0", name)
+
+	// which the old hardware dropping it doesn't undo
+	_, err = hw.Add(`{"id":"` + id1 + `","network_ports":[{"name":"eth1","connected_ports":[{"name":"xe-0/0/12","hardware":{"hostname":"leaf-4"}}]}]}`)
+	assert.NoError(err)
+
+	j, _, err = hw.ByPort("leaf-3", "xe-0/0/12")
+	assert.NoError(err)
+	assert.Equal(hw2, j)
+
+	_, err = hw.Add(`{"id":"` + id1 + `","state":"deleted"}`)
+	assert.NoError(err)
+
+	j, _, err = hw.ByPort("leaf-4", "xe-0/0/12")
+	assert.NoError(err)
+	assert.Empty(j)
+	assert.Len(hw.byPort, 1)
+}
+
+func TestByIPMatch(t *testing.T) {
+	assert := require.New(t)
+
+	hw := New()
+
+	j := `{"id":"00000000-0000-0000-0000-000000000001",` +
+		`"ip_addresses":[{"address":"10.0.0.1","management":true},{"address":"2604:1380::1","public":true}],` +
+		`"instance":{"ip_addresses":[{"address":"147.75.0.1","public":true,"management":true},{"address":"10.1.0.1"}]},` +
+		`"network_ports":[{"name":"eth0","data":{"mac":"00:00:00:00:00:01"}},{"name":"eth1","data":{"mac":"00:00:00:00:00:02"}}]}`
+	_, err := hw.Add(j)
+	assert.NoError(err)
+
+	for _, test := range []struct {
+		ip    string
+		match IPMatch
+	}{
+		{ip: "10.0.0.1", match: IPMatch{Family: 4, Management: true, Source: SourceIPAddresses}},
+		{ip: "2604:1380::1", match: IPMatch{Family: 6, Public: true, Source: SourceIPAddresses}},
+		{ip: "147.75.0.1", match: IPMatch{Family: 4, Management: true, Public: true, Source: SourceInstanceIPAddresses}},
+		{ip: "10.1.0.1", match: IPMatch{Family: 4, Source: SourceInstanceIPAddresses}},
+	} {
+		got, rev, match, err := hw.ByIPMatch(test.ip)
+		assert.NoError(err)
+		assert.Equal(j, got)
+		assert.Equal(uint64(1), rev)
+		assert.Equal(test.match, match, test.ip)
+	}
+
+	got, _, match, err := hw.ByIPMatch("10.2.0.1")
+	assert.NoError(err)
+	assert.Empty(got)
+	assert.Equal(IPMatch{}, match)
+
+	for mac, port := range map[string]string{"00:00:00:00:00:01": "eth0", "00:00:00:00:00:02": "eth1", "00:00:00:00:00:03": ""} {
+		_, _, got, err := hw.ByMACPort(mac)
+		assert.NoError(err)
+		assert.Equal(port, got, mac)
+	}
+
+	_, err = hw.Add(`{"id":"00000000-0000-0000-0000-000000000001","state":"deleted"}`)
+	assert.NoError(err)
+	assert.Empty(hw.byIP)
+	assert.Empty(hw.byMAC)
+}
+
 func TestByMAC(t *testing.T) {
 	assert := require.New(t)
 
@@ -403,3 +650,45 @@ func TestByMAC(t *testing.T) {
 		assert.Equal(j2, j)
 	}
 }
+
+func TestUnchanged(t *testing.T) {
+	assert := require.New(t)
+
+	id := "00000000-0000-0000-0000-000000000001"
+	hw := New(Revisions(5))
+
+	j := `{"id":"` + id + `","state":"active","plan":{"cpus":1}}`
+	_, err := hw.Add(j)
+	assert.NoError(err)
+
+	// same document with keys reordered and spaces added
+	w, err := hw.CompareAndSwap(`{ "plan": {"cpus": 1}, "state": "active", "id": "`+id+`" }`, FromPush, 1)
+	assert.NoError(err)
+	assert.Equal(Written{ID: id, Old: j, Revision: 1, Unchanged: true}, w)
+
+	got, err := hw.ByID(id)
+	assert.NoError(err)
+	assert.Equal(j, got)
+	assert.Len(hw.History(id), 1)
+
+	w, err = hw.CompareAndSwap(`{"id":"`+id+`","state":"active","plan":{"cpus":1.0}}`, FromPush, 0)
+	assert.NoError(err)
+	assert.False(w.Unchanged)
+	assert.Equal(uint64(2), w.Revision)
+}
+
+func TestInvalidDocument(t *testing.T) {
+	assert := require.New(t)
+
+	hw := New()
+	for _, j := range []string{
+		`not json`,
+		`{"id":"1"}`,
+		`{"id":"00000000-0000-0000-0000-000000000001","ip_addresses":[{"address":"10.0.0"}]}`,
+		`{"id":"00000000-0000-0000-0000-000000000001","instance":{"ip_addresses":[{"address":"::g"}]}}`,
+		`{"id":"00000000-0000-0000-0000-000000000001","network_ports":[{"data":{"mac":"00:00"}}]}`,
+	} {
+		_, err := hw.Add(j)
+		assert.ErrorIs(err, ErrInvalidDocument, j)
+	}
+}
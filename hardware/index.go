@@ -0,0 +1,183 @@
+package hardware
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// IndexSpec declares a secondary index on the values found at a dotted JSON path, e.g. "instance.hostname".
+// Paths go through arrays, so "network_ports.name" indexes the name of every port.
+// A unique index maps each value to a single hardware, the last one added, like the ip and mac indexes do.
+// Otherwise every hardware with the value is kept.
+type IndexSpec struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Unique bool   `json:"unique"`
+}
+
+// ErrUnknownIndex is returned by ByIndex for indexes that were not declared.
+var ErrUnknownIndex = errors.New("unknown index")
+
+type index struct {
+	spec IndexSpec
+	keys []string
+	ids  map[string]map[id]bool
+}
+
+// Indexes declares secondary indexes to maintain, which can be queried with ByIndex.
+func Indexes(specs ...IndexSpec) Option {
+	return func(h *Hardware) {
+		for _, spec := range specs {
+			h.indexes[spec.Name] = &index{
+				spec: spec,
+				keys: strings.Split(spec.Path, "."),
+				ids:  map[string]map[id]bool{},
+			}
+		}
+	}
+}
+
+// ValidateIndexSpecs checks specs have unique names and valid paths.
+func ValidateIndexSpecs(specs []IndexSpec) error {
+	names := map[string]bool{}
+	for i, spec := range specs {
+		if spec.Name == "" {
+			return errors.Errorf("index %d needs a name", i)
+		}
+
+		if names[spec.Name] {
+			return errors.Errorf("index %q is a duplicate", spec.Name)
+		}
+
+		names[spec.Name] = true
+
+		for _, k := range strings.Split(spec.Path, ".") {
+			if k == "" {
+				return errors.Errorf("index %q has invalid path %q", spec.Name, spec.Path)
+			}
+		}
+	}
+
+	return nil
+}
+
+// indexValues returns the values of the indexed paths in j, by index name.
+func (h *Hardware) indexValues(j string) (map[string]map[string]bool, error) {
+	if len(h.indexes) == 0 {
+		return nil, nil
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal([]byte(j), &doc); err != nil {
+		return nil, errors.Wrap(err, "unable to decode json")
+	}
+
+	vals := make(map[string]map[string]bool, len(h.indexes))
+	for name, idx := range h.indexes {
+		vals[name] = map[string]bool{}
+		collectValues(doc, idx.keys, vals[name])
+	}
+
+	return vals, nil
+}
+
+// collectValues adds the scalar values at the path keys in v to vals, nulls and empty strings are skipped.
+func collectValues(v interface{}, keys []string, vals map[string]bool) {
+	switch v := v.(type) {
+	case []interface{}:
+		for _, e := range v {
+			collectValues(e, keys, vals)
+		}
+
+		return
+	case map[string]interface{}:
+		if len(keys) > 0 {
+			collectValues(v[keys[0]], keys[1:], vals)
+		}
+
+		return
+	}
+
+	if len(keys) > 0 {
+		return
+	}
+
+	switch v := v.(type) {
+	case string:
+		if v != "" {
+			vals[v] = true
+		}
+	case float64:
+		vals[strconv.FormatFloat(v, 'f', -1, 64)] = true
+	case bool:
+		vals[strconv.FormatBool(v)] = true
+	}
+}
+
+// updateIndexes moves the entries of id in the secondary indexes from the values before to the ones after.
+// A nil after removes id from the indexes.
+func (h *Hardware) updateIndexes(v id, before, after map[string]map[string]bool) {
+	for name, idx := range h.indexes {
+		for val := range before[name] {
+			if after[name][val] {
+				continue
+			}
+
+			if ids := idx.ids[val]; ids[v] {
+				delete(ids, v)
+
+				if len(ids) == 0 {
+					delete(idx.ids, val)
+				}
+			}
+		}
+
+		for val := range after[name] {
+			if idx.spec.Unique {
+				idx.ids[val] = map[id]bool{v: true}
+
+				continue
+			}
+
+			if idx.ids[val] == nil {
+				idx.ids[val] = map[id]bool{}
+			}
+
+			idx.ids[val][v] = true
+		}
+	}
+}
+
+// ByIndex returns the hardware with value in the named secondary index, ordered by id.
+func (h *Hardware) ByIndex(name, value string) ([]string, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	idx, ok := h.indexes[name]
+	if !ok {
+		return nil, errors.Wrap(ErrUnknownIndex, name)
+	}
+
+	ids := make([]id, 0, len(idx.ids[value]))
+	for v := range idx.ids[value] {
+		ids = append(ids, v)
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	js := make([]string, 0, len(ids))
+	for _, v := range ids {
+		j, err := h.codec.Load().decode(h.hw[v].j)
+		if err != nil {
+			return nil, err
+		}
+
+		js = append(js, j)
+	}
+
+	return js, nil
+}
@@ -0,0 +1,81 @@
+package hardware
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestByIndex(t *testing.T) {
+	assert := require.New(t)
+
+	hw := New(Indexes(
+		IndexSpec{Name: "hostname", Path: "hostname", Unique: true},
+		IndexSpec{Name: "plan", Path: "plan.slug"},
+		IndexSpec{Name: "port", Path: "network_ports.name"},
+		IndexSpec{Name: "vlan", Path: "network_ports.data.vlans"},
+	))
+
+	id1, id2 := "00000000-0000-0000-0000-000000000001", "00000000-0000-0000-0000-000000000002"
+	hw1 := `{"id":"` + id1 + `","hostname":"a","plan":{"slug":"c3.small"},"network_ports":[{"name":"eth0","data":{"vlans":[1000,1001]}},{"name":"eth1"}]}`
+	hw2 := `{"id":"` + id2 + `","hostname":"b","plan":{"slug":"c3.small"},"network_ports":[{"name":"eth0","data":{"vlans":[1001]}}]}`
+
+	for _, j := range []string{hw2, hw1} {
+		_, err := hw.Add(j)
+		assert.NoError(err)
+	}
+
+	for _, test := range []struct {
+		name, value string
+		want        []string
+	}{
+		{name: "hostname", value: "a", want: []string{hw1}},
+		{name: "hostname", value: "c", want: []string{}},
+		{name: "plan", value: "c3.small", want: []string{hw1, hw2}},
+		{name: "port", value: "eth1", want: []string{hw1}},
+		{name: "port", value: "eth0", want: []string{hw1, hw2}},
+		{name: "vlan", value: "1000", want: []string{hw1}},
+		{name: "vlan", value: "1001", want: []string{hw1, hw2}},
+	} {
+		js, err := hw.ByIndex(test.name, test.value)
+		assert.NoError(err)
+		assert.Equal(test.want, js, "%s=%s", test.name, test.value)
+	}
+
+	_, err := hw.ByIndex("serial", "x")
+	assert.ErrorIs(err, ErrUnknownIndex)
+
+	// changed values move, unique values go to the last hardware added with them
+	hw1 = `{"id":"` + id1 + `","hostname":"b","plan":{"slug":"m3.large"}}`
+	_, err = hw.Add(hw1)
+	assert.NoError(err)
+
+	for _, test := range []struct {
+		name, value string
+		want        []string
+	}{
+		{name: "hostname", value: "a", want: []string{}},
+		{name: "hostname", value: "b", want: []string{hw1}},
+		{name: "plan", value: "c3.small", want: []string{hw2}},
+		{name: "plan", value: "m3.large", want: []string{hw1}},
+		{name: "port", value: "eth1", want: []string{}},
+	} {
+		js, err := hw.ByIndex(test.name, test.value)
+		assert.NoError(err)
+		assert.Equal(test.want, js, "%s=%s", test.name, test.value)
+	}
+
+	_, err = hw.Add(`{"id":"` + id1 + `","state":"deleted"}`)
+	assert.NoError(err)
+
+	for _, idx := range hw.indexes {
+		for _, ids := range idx.ids {
+			assert.NotContains(ids, id(id1))
+		}
+	}
+
+	assert.NoError(ValidateIndexSpecs([]IndexSpec{{Name: "a", Path: "a.b"}}))
+	assert.Error(ValidateIndexSpecs([]IndexSpec{{Path: "a"}}))
+	assert.Error(ValidateIndexSpecs([]IndexSpec{{Name: "a", Path: "a..b"}}))
+	assert.Error(ValidateIndexSpecs([]IndexSpec{{Name: "a", Path: "a"}, {Name: "a", Path: "b"}}))
+}
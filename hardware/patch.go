@@ -0,0 +1,106 @@
+package hardware
+
+import (
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/pkg/errors"
+)
+
+// Kinds of patches accepted by Patch.
+const (
+	MergePatch = "merge" // RFC 7396 JSON merge patch
+	JSONPatch  = "json"  // RFC 6902 JSON patch
+)
+
+var (
+	// ErrNotFound is returned (wrapped) by Patch for hardware that isn't stored, or was deleted.
+	ErrNotFound = errors.New("hardware not found")
+	// ErrInvalidPatch is returned (wrapped) by Patch for patches that can't be applied.
+	ErrInvalidPatch = errors.New("invalid patch")
+)
+
+// Patch applies patch, a MergePatch or JSONPatch (MergePatch if kind is ""), to the hardware with the given id and
+// stores the result like CompareAndSwap would. Reading, patching and storing the document happen under the same lock
+// so no other write can come in between.
+// It returns the patched document.
+func (h *Hardware) Patch(v, kind, patch, source string, rev uint64) (string, Written, error) {
+	v = strings.TrimSpace(strings.ToLower(v))
+
+	h.mu.Lock()
+	j, w, err := h.patch(v, kind, patch, source, rev)
+	seq := h.seq
+	h.mu.Unlock()
+
+	h.publish(seq)
+
+	if err == nil {
+		h.notify([]Written{w}, []string{j})
+	}
+
+	return j, w, err
+}
+
+// patch is Patch, must be called with the lock held.
+func (h *Hardware) patch(v, kind, patch, source string, rev uint64) (string, Written, error) {
+	og, ok := h.hw[id(v)]
+	if _, dead := h.tombstones[id(v)]; !ok || dead {
+		return "", Written{}, errors.Wrap(ErrNotFound, v)
+	}
+
+	if rev != 0 && og.rev != rev {
+		return "", Written{}, errors.Wrapf(ErrRevisionMismatch, "%s is at revision %d, not %d", v, og.rev, rev)
+	}
+
+	j, err := h.codec.Load().decode(og.j)
+	if err != nil {
+		return "", Written{}, err
+	}
+
+	b, err := applyPatch(kind, []byte(j), []byte(patch))
+	if err != nil {
+		return "", Written{}, err
+	}
+
+	d, err := h.parse(string(b))
+	if err != nil {
+		return "", Written{}, errors.Wrap(ErrInvalidPatch, err.Error())
+	}
+
+	if !strings.EqualFold(d.hw.ID, v) {
+		return "", Written{}, errors.Wrap(ErrInvalidPatch, "the id can not be changed")
+	}
+
+	w, err := h.store(d, source, 0)
+	if err != nil {
+		return "", Written{}, err
+	}
+
+	return d.j, w, nil
+}
+
+func applyPatch(kind string, j, patch []byte) ([]byte, error) {
+	switch kind {
+	case "", MergePatch:
+		b, err := jsonpatch.MergePatch(j, patch)
+		if err != nil {
+			return nil, errors.Wrap(ErrInvalidPatch, err.Error())
+		}
+
+		return b, nil
+	case JSONPatch:
+		p, err := jsonpatch.DecodePatch(patch)
+		if err != nil {
+			return nil, errors.Wrap(ErrInvalidPatch, err.Error())
+		}
+
+		b, err := p.Apply(j)
+		if err != nil {
+			return nil, errors.Wrap(ErrInvalidPatch, err.Error())
+		}
+
+		return b, nil
+	}
+
+	return nil, errors.Wrapf(ErrInvalidPatch, "unknown kind %q", kind)
+}
@@ -0,0 +1,83 @@
+package hardware
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatch(t *testing.T) {
+	assert := require.New(t)
+
+	const (
+		id1 = "00000000-0000-0000-0000-000000000001"
+		id2 = "00000000-0000-0000-0000-000000000002"
+	)
+
+	hw := New(Indexes(IndexSpec{Name: "state", Path: "state"}))
+
+	_, err := hw.Add(`{"id":"` + id1 + `","state":"provisioning","allow_pxe":true,"ip_addresses":[{"address":"10.0.0.1"}]}`)
+	assert.NoError(err)
+
+	j, w, err := hw.Patch(id1, MergePatch, `{"allow_pxe":false,"state":"active"}`, FromPush, 1)
+	assert.NoError(err)
+	assert.Contains(w.Old, `"allow_pxe":true`)
+	assert.Contains(j, `"allow_pxe":false`)
+	assert.Equal(uint64(2), w.Revision)
+
+	// indexes follow the patched document
+	js, err := hw.ByIndex("state", "active")
+	assert.NoError(err)
+	assert.Equal([]string{j}, js)
+
+	j, w, err = hw.Patch(id1, JSONPatch, `[{"op":"replace","path":"/ip_addresses/0/address","value":"10.0.0.2"}]`, FromPush, 0)
+	assert.NoError(err)
+	assert.Equal(uint64(3), w.Revision)
+
+	// a patch that changes nothing stores nothing
+	_, w, err = hw.Patch(id1, MergePatch, `{"state":"active"}`, FromPush, 0)
+	assert.NoError(err)
+	assert.True(w.Unchanged)
+	assert.Equal(uint64(3), w.Revision)
+
+	got, err := hw.ByIP("10.0.0.2")
+	assert.NoError(err)
+	assert.Equal(j, got)
+
+	got, err = hw.ByIP("10.0.0.1")
+	assert.NoError(err)
+	assert.Empty(got)
+
+	for _, test := range []struct {
+		id, kind, patch string
+		rev             uint64
+		err             error
+	}{
+		{id: id2, patch: `{"state":"active"}`, err: ErrNotFound},
+		{id: id1, patch: `{"state":"active"}`, rev: 1, err: ErrRevisionMismatch},
+		{id: id1, patch: `{"id":"` + id2 + `"}`, err: ErrInvalidPatch},
+		{id: id1, patch: `{"ip_addresses":[{"address":"nope"}]}`, err: ErrInvalidPatch},
+		{id: id1, kind: JSONPatch, patch: `[{"op":"remove","path":"/missing"}]`, err: ErrInvalidPatch},
+		{id: id1, kind: JSONPatch, patch: `{}`, err: ErrInvalidPatch},
+		{id: id1, kind: "xml", patch: `{}`, err: ErrInvalidPatch},
+	} {
+		_, _, err := hw.Patch(test.id, test.kind, test.patch, FromPush, test.rev)
+		assert.ErrorIs(err, test.err, test.patch)
+	}
+
+	// failed patches change nothing
+	got, err = hw.ByID(id1)
+	assert.NoError(err)
+	assert.Equal(j, got)
+
+	// patching the state to deleted deletes the hardware
+	_, _, err = hw.Patch(id1, MergePatch, `{"state":"deleted"}`, FromPush, 0)
+	assert.NoError(err)
+
+	got, err = hw.ByIP("10.0.0.2")
+	assert.NoError(err)
+	assert.Empty(got)
+
+	_, _, err = hw.Patch(id1, MergePatch, `{"state":"active"}`, FromPush, 0)
+	assert.ErrorIs(err, ErrNotFound)
+}
@@ -0,0 +1,201 @@
+package hardware
+
+import (
+	"embed"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/packethost/pkg/log"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+//go:embed schema/*.json
+var schemas embed.FS
+
+// LatestSchema is the most recent version of the hardware document schema.
+const LatestSchema = "v1"
+
+// SchemaVersions returns the versions of the hardware document schema, sorted.
+func SchemaVersions() []string {
+	entries, _ := schemas.ReadDir("schema")
+
+	versions := make([]string, 0, len(entries))
+	for _, e := range entries {
+		versions = append(versions, strings.TrimSuffix(e.Name(), ".json"))
+	}
+
+	sort.Strings(versions)
+
+	return versions
+}
+
+// SchemaDocument returns the JSON Schema of the given version of the hardware document.
+func SchemaDocument(version string) ([]byte, error) {
+	b, err := schemas.ReadFile("schema/" + version + ".json")
+	if err != nil {
+		return nil, errors.Errorf("unknown schema version %q", version)
+	}
+
+	return b, nil
+}
+
+// SchemaMode decides what happens to documents violating the schema.
+type SchemaMode string
+
+const (
+	// SchemaOff doesn't validate documents.
+	SchemaOff SchemaMode = "off"
+	// SchemaWarn logs and counts documents violating the schema, but stores them.
+	SchemaWarn SchemaMode = "warn"
+	// SchemaStrict fails storing documents violating the schema with a *SchemaError.
+	SchemaStrict SchemaMode = "strict"
+)
+
+// ParseSchemaMode returns the mode named s.
+func ParseSchemaMode(s string) (SchemaMode, error) {
+	switch m := SchemaMode(s); m {
+	case SchemaOff, SchemaWarn, SchemaStrict:
+		return m, nil
+	}
+
+	return "", errors.Errorf("unknown schema mode %q", s)
+}
+
+// ErrSchema is matched by the *SchemaError returned for documents violating the schema in SchemaStrict mode.
+var ErrSchema = errors.New("document violates the hardware schema")
+
+// Violation is a part of a document violating the schema.
+type Violation struct {
+	Pointer string // JSON pointer to the offending value, "" for the whole document
+	Message string
+}
+
+// SchemaError lists how a document violates a version of the schema.
+type SchemaError struct {
+	Version    string
+	Violations []Violation // sorted by pointer
+}
+
+func (e *SchemaError) Error() string {
+	vs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		vs[i] = strconv.Quote(v.Pointer) + ": " + v.Message
+	}
+
+	return "hardware schema " + e.Version + ": " + strings.Join(vs, "; ")
+}
+
+// Is makes the error match ErrSchema.
+func (e *SchemaError) Is(target error) bool {
+	return target == ErrSchema
+}
+
+// Validator checks documents against a version of the hardware schema.
+// A nil Validator accepts every document.
+type Validator struct {
+	// Logger, if set, logs the documents violating the schema in SchemaWarn mode.
+	Logger *log.Logger
+	// Violations, if set, counts the documents violating the schema, labeled by mode.
+	Violations *prometheus.CounterVec
+
+	mode    SchemaMode
+	version string
+	schema  *jsonschema.Schema
+}
+
+// NewValidator returns a Validator for the given version of the schema, see SchemaVersions.
+func NewValidator(version string, mode SchemaMode) (*Validator, error) {
+	if _, err := ParseSchemaMode(string(mode)); err != nil {
+		return nil, err
+	}
+
+	b, err := SchemaDocument(version)
+	if err != nil {
+		return nil, err
+	}
+
+	c := jsonschema.NewCompiler()
+	c.Draft = jsonschema.Draft2020
+	c.AssertFormat = true
+
+	url := "schema/" + version + ".json"
+	if err := c.AddResource(url, strings.NewReader(string(b))); err != nil {
+		return nil, errors.Wrap(err, "load schema "+version)
+	}
+
+	s, err := c.Compile(url)
+	if err != nil {
+		return nil, errors.Wrap(err, "compile schema "+version)
+	}
+
+	return &Validator{mode: mode, version: version, schema: s}, nil
+}
+
+// Validate checks j against the schema.
+// In SchemaStrict mode violations are returned as a *SchemaError, in SchemaWarn mode they are only logged and counted.
+func (v *Validator) Validate(j string) error {
+	if v == nil || v.mode == SchemaOff {
+		return nil
+	}
+
+	dec := json.NewDecoder(strings.NewReader(j))
+	dec.UseNumber()
+
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return invalidError{errors.Wrap(err, "unable to decode json")}
+	}
+
+	err := v.schema.Validate(doc)
+	if err == nil {
+		return nil
+	}
+
+	var ve *jsonschema.ValidationError
+	if !errors.As(err, &ve) {
+		return errors.Wrap(err, "validate against schema "+v.version)
+	}
+
+	vs := violations(ve, nil)
+	sort.SliceStable(vs, func(i, j int) bool { return vs[i].Pointer < vs[j].Pointer })
+
+	serr := &SchemaError{Version: v.version, Violations: vs}
+
+	if v.Violations != nil {
+		v.Violations.With(prometheus.Labels{"mode": string(v.mode)}).Inc()
+	}
+
+	if v.mode == SchemaStrict {
+		return serr
+	}
+
+	if v.Logger != nil {
+		v.Logger.With("json", j).Error(serr)
+	}
+
+	return nil
+}
+
+// violations flattens ve into the violations it was caused by.
+func violations(ve *jsonschema.ValidationError, vs []Violation) []Violation {
+	if len(ve.Causes) == 0 {
+		vs = append(vs, Violation{Pointer: ve.InstanceLocation, Message: ve.Message})
+	}
+
+	for _, c := range ve.Causes {
+		vs = violations(c, vs)
+	}
+
+	return vs
+}
+
+// Schema will validate documents with v before storing them.
+func Schema(v *Validator) Option {
+	return func(h *Hardware) {
+		h.validator = v
+	}
+}
@@ -0,0 +1,123 @@
+package hardware
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema(t *testing.T) {
+	const valid = `{"id":"5ed553c8-8eab-496a-bbf0-73ce1f900390","state":"in_use",` +
+		`"ip_addresses":[{"address":"192.168.0.1","management":true,"public":false}],` +
+		`"network_ports":[{"name":"eth0","data":{"mac":"00:00:00:00:00:01"}}]}`
+
+	for _, test := range []struct {
+		name       string
+		j          string
+		violations []Violation
+	}{
+		{name: "valid", j: valid},
+		{name: "deleted", j: `{"id":"5ed553c8-8eab-496a-bbf0-73ce1f900390","state":"deleted"}`},
+		{
+			name: "missing network_ports",
+			j:    `{"id":"5ed553c8-8eab-496a-bbf0-73ce1f900390","state":"in_use"}`,
+			violations: []Violation{
+				{Pointer: "", Message: "missing properties: 'network_ports'"},
+			},
+		},
+		{
+			name: "wrongly typed",
+			j:    `{"id":"5ed553c8-8eab-496a-bbf0-73ce1f900390","state":1,"network_ports":[{"name":"eth0","data":{"mac":1}}]}`,
+			violations: []Violation{
+				{Pointer: "/network_ports/0/data/mac", Message: "expected string or null, but got number"},
+				{Pointer: "/state", Message: "expected string, but got number"},
+			},
+		},
+		{
+			name: "bad id",
+			j:    `{"id":"0","state":"in_use","network_ports":[]}`,
+			violations: []Violation{
+				{Pointer: "/id", Message: "'0' is not valid 'uuid'"},
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			assert := require.New(t)
+
+			v, err := NewValidator(LatestSchema, SchemaStrict)
+			assert.NoError(err)
+
+			err = v.Validate(test.j)
+			if test.violations == nil {
+				assert.NoError(err)
+
+				return
+			}
+
+			assert.True(errors.Is(err, ErrSchema), err)
+
+			var serr *SchemaError
+			assert.True(errors.As(err, &serr))
+			assert.Equal(LatestSchema, serr.Version)
+			assert.Equal(test.violations, serr.Violations)
+		})
+	}
+}
+
+func TestSchemaModes(t *testing.T) {
+	assert := require.New(t)
+
+	const bad = `{"id":"5ed553c8-8eab-496a-bbf0-73ce1f900390","state":1}`
+
+	violations := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "violations"}, []string{"mode"})
+
+	strict, err := NewValidator(LatestSchema, SchemaStrict)
+	assert.NoError(err)
+	strict.Violations = violations
+
+	h := New(Schema(strict))
+	_, err = h.Add(bad)
+	assert.ErrorIs(err, ErrSchema)
+	assert.Contains(err.Error(), `"/state": expected string, but got number`)
+	assert.Empty(h.hw)
+	assert.Equal(1.0, testutil.ToFloat64(violations.WithLabelValues("strict")))
+
+	warn, err := NewValidator(LatestSchema, SchemaWarn)
+	assert.NoError(err)
+	warn.Violations = violations
+
+	h = New(Schema(warn))
+	_, err = h.Add(`{"id":"5ed553c8-8eab-496a-bbf0-73ce1f900390","state":"in_use"}`)
+	assert.NoError(err)
+	assert.Len(h.hw, 1)
+	assert.Equal(1.0, testutil.ToFloat64(violations.WithLabelValues("warn")))
+
+	off, err := NewValidator(LatestSchema, SchemaOff)
+	assert.NoError(err)
+	assert.NoError(off.Validate(bad))
+
+	_, err = NewValidator("v0", SchemaStrict)
+	assert.Error(err)
+
+	_, err = NewValidator(LatestSchema, "lenient")
+	assert.Error(err)
+}
+
+func TestSchemaVersions(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Contains(SchemaVersions(), LatestSchema)
+
+	for _, version := range SchemaVersions() {
+		b, err := SchemaDocument(version)
+		assert.NoError(err)
+		assert.True(json.Valid(b), version)
+
+		_, err = NewValidator(version, SchemaStrict)
+		assert.NoError(err, version)
+	}
+}
@@ -0,0 +1,239 @@
+package hardware
+
+import (
+	"time"
+
+	"inet.af/netaddr"
+)
+
+// nshards is the number of shards of a cowMap.
+const nshards = 256
+
+// shards is a version of a cowMap, never written to once published.
+type shards[K comparable, V any] [nshards]map[K]V
+
+func (s *shards[K, V]) get(hash uint32, k K) (V, bool) {
+	v, ok := s[hash%nshards][k]
+
+	return v, ok
+}
+
+// flatten returns every entry of s in a single map.
+func (s *shards[K, V]) flatten() map[K]V {
+	m := map[K]V{}
+	for _, shard := range s {
+		for k, v := range shard {
+			m[k] = v
+		}
+	}
+
+	return m
+}
+
+// cowMap is a copy on write map whose versions can be read without locking while the next one is written.
+// Publishing a version only copies the shards written to since the previous one, so writes don't cost a copy of the
+// whole map. Writing and publishing must be serialized by the caller.
+type cowMap[K comparable, V any] struct {
+	hash func(K) uint32
+	pub  *shards[K, V]
+	next shards[K, V] // copies of the shards written to since pub, nil for the others
+}
+
+func newCowMap[K comparable, V any](hash func(K) uint32) cowMap[K, V] {
+	pub := &shards[K, V]{}
+	for i := range pub {
+		pub[i] = map[K]V{}
+	}
+
+	return cowMap[K, V]{hash: hash, pub: pub}
+}
+
+// shard returns the writable copy of the shard of k.
+func (m *cowMap[K, V]) shard(k K) map[K]V {
+	i := m.hash(k) % nshards
+	if m.next[i] == nil {
+		m.next[i] = make(map[K]V, len(m.pub[i])+1)
+		for k, v := range m.pub[i] {
+			m.next[i][k] = v
+		}
+	}
+
+	return m.next[i]
+}
+
+func (m *cowMap[K, V]) set(k K, v V) {
+	m.shard(k)[k] = v
+}
+
+func (m *cowMap[K, V]) delete(k K) {
+	if _, ok := m.pub.get(m.hash(k), k); !ok && m.next[m.hash(k)%nshards] == nil {
+		return
+	}
+
+	delete(m.shard(k), k)
+}
+
+// publish returns the new version, sharing the shards that weren't written to with the previous one.
+func (m *cowMap[K, V]) publish() *shards[K, V] {
+	pub := *m.pub
+	for i, s := range m.next {
+		if s != nil {
+			pub[i] = s
+			m.next[i] = nil
+		}
+	}
+
+	m.pub = &pub
+
+	return m.pub
+}
+
+// fnv hashes s with 32 bit FNV-1a.
+func fnv(s string) uint32 {
+	h := uint32(2166136261)
+	for i := 0; i < len(s); i++ {
+		h ^= uint32(s[i])
+		h *= 16777619
+	}
+
+	return h
+}
+
+func hashID(v id) uint32 {
+	return fnv(string(v))
+}
+
+func hashMAC(v mac) uint32 {
+	return fnv(string(v))
+}
+
+func hashIP(ip netaddr.IP) uint32 {
+	b := ip.As16()
+
+	return fnv(string(b[:]))
+}
+
+func hashSwitchPort(sp switchPort) uint32 {
+	return fnv(sp.sw) ^ fnv(sp.port)*16777619
+}
+
+// entry is a hardware as seen by the lookups of a snapshot.
+type entry struct {
+	j       string // compressed with the snapshot's codec
+	rev     uint64
+	ips     map[netaddr.IP]IPMatch // never written to once stored
+	macs    map[mac]string
+	deleted time.Time // zero unless it is a tombstone
+}
+
+// snapshot is an immutable version of the lookups by id, ip and mac address and switch port, which are served from the
+// latest one without taking the lock.
+type snapshot struct {
+	codec  *codec // the documents of hw are compressed with
+	hw     *shards[id, entry]
+	byIP   *shards[netaddr.IP, id]
+	byMAC  *shards[mac, id]
+	byPort *shards[switchPort, portLink]
+}
+
+// view is where the next snapshot is written to, tracking what changed since the last one.
+type view struct {
+	hw     cowMap[id, entry]
+	byIP   cowMap[netaddr.IP, id]
+	byMAC  cowMap[mac, id]
+	byPort cowMap[switchPort, portLink]
+
+	// keys written to since the last snapshot
+	ids   map[id]bool
+	ips   map[netaddr.IP]bool
+	macs  map[mac]bool
+	ports map[switchPort]bool
+}
+
+func newView() view {
+	return view{
+		hw:     newCowMap[id, entry](hashID),
+		byIP:   newCowMap[netaddr.IP, id](hashIP),
+		byMAC:  newCowMap[mac, id](hashMAC),
+		byPort: newCowMap[switchPort, portLink](hashSwitchPort),
+		ids:    map[id]bool{},
+		ips:    map[netaddr.IP]bool{},
+		macs:   map[mac]bool{},
+		ports:  map[switchPort]bool{},
+	}
+}
+
+// touch marks v as changed for the next snapshot.
+// Must be called with the lock held, along with setIP, deleteIP, setMAC, deleteMAC, setPort and deletePort for its
+// addresses and ports.
+func (h *Hardware) touch(v id) {
+	h.seq++
+	h.view.ids[v] = true
+}
+
+// publish makes the writes stored up to seq visible to the lookups served from snapshots, unless a concurrent writer
+// already did. Writers stored while waiting are published together, so only one of them copies what they changed.
+// Must be called without the lock held.
+func (h *Hardware) publish(seq uint64) {
+	h.pubMu.Lock()
+	defer h.pubMu.Unlock()
+
+	if h.published >= seq {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	v := &h.view
+
+	for k := range v.ids {
+		hw, ok := h.hw[k]
+		if ok {
+			v.hw.set(k, entry{j: hw.j, rev: hw.rev, ips: hw.ips, macs: hw.macs, deleted: h.tombstones[k]})
+		} else {
+			v.hw.delete(k)
+		}
+
+		delete(v.ids, k)
+	}
+
+	for ip := range v.ips {
+		if owner, ok := h.byIP[ip]; ok {
+			v.byIP.set(ip, owner)
+		} else {
+			v.byIP.delete(ip)
+		}
+
+		delete(v.ips, ip)
+	}
+
+	for m := range v.macs {
+		if owner, ok := h.byMAC[m]; ok {
+			v.byMAC.set(m, owner)
+		} else {
+			v.byMAC.delete(m)
+		}
+
+		delete(v.macs, m)
+	}
+
+	for sp := range v.ports {
+		if l, ok := h.byPort[sp]; ok {
+			v.byPort.set(sp, l)
+		} else {
+			v.byPort.delete(sp)
+		}
+
+		delete(v.ports, sp)
+	}
+
+	h.snap.Store(&snapshot{
+		codec:  h.codec.Load(),
+		hw:     v.hw.publish(),
+		byIP:   v.byIP.publish(),
+		byMAC:  v.byMAC.publish(),
+		byPort: v.byPort.publish(),
+	})
+	h.published = h.seq
+}
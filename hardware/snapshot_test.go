@@ -0,0 +1,202 @@
+package hardware
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCowMap(t *testing.T) {
+	assert := require.New(t)
+
+	m := newCowMap[id, int](hashID)
+	m.set("a", 1)
+	m.set("b", 2)
+
+	v1 := m.publish()
+
+	m.set("a", 3)
+	m.delete("b")
+	m.delete("c")
+
+	// the published version doesn't see writes until the next one is published
+	v, ok := v1.get(hashID("a"), "a")
+	assert.True(ok)
+	assert.Equal(1, v)
+
+	v2 := m.publish()
+
+	v, ok = v2.get(hashID("a"), "a")
+	assert.True(ok)
+	assert.Equal(3, v)
+
+	_, ok = v2.get(hashID("b"), "b")
+	assert.False(ok)
+
+	v, ok = v1.get(hashID("b"), "b")
+	assert.True(ok)
+	assert.Equal(2, v)
+
+	// untouched shards are shared
+	shared := 0
+	for i := range v1 {
+		if reflect.ValueOf(v1[i]).Pointer() == reflect.ValueOf(v2[i]).Pointer() {
+			shared++
+		}
+	}
+	assert.GreaterOrEqual(shared, nshards-2)
+}
+
+func TestSnapshotConcurrency(t *testing.T) {
+	assert := require.New(t)
+
+	const n = 100
+
+	js, macs := benchHardware(n)
+	h := New()
+
+	wg := sync.WaitGroup{}
+
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+
+		go func(w int) {
+			defer wg.Done()
+
+			for i := w; i < n*4; i += 4 {
+				j := js[i%n][i/n%2]
+				if _, err := h.Add(j); err != nil {
+					panic(err)
+				}
+
+				// writes are visible to lookups once Add returns
+				got, err := h.ByMAC(macs[i%n])
+				if err != nil {
+					panic(err)
+				}
+
+				if got == "" {
+					panic("missing " + macs[i%n])
+				}
+			}
+		}(w)
+
+		wg.Add(1)
+
+		go func(w int) {
+			defer wg.Done()
+
+			for i := w; i < n*4; i++ {
+				// a lookup never mixes up hardware
+				got, err := h.ByMAC(macs[i%n])
+				if err != nil {
+					panic(err)
+				}
+
+				if got != "" && got != js[i%n][0] && got != js[i%n][1] {
+					panic("mismatch for " + macs[i%n] + ": " + got)
+				}
+			}
+		}(w)
+	}
+
+	wg.Wait()
+
+	for i, m := range macs {
+		got, err := h.ByMAC(m)
+		assert.NoError(err)
+		assert.Equal(js[i][1], got)
+	}
+}
+
+// benchHardware returns n hardware documents, two differing versions of each, with their mac addresses.
+func benchHardware(n int) ([][2]string, []string) {
+	js := make([][2]string, n)
+	macs := make([]string, n)
+
+	for i := range js {
+		macs[i] = fmt.Sprintf("02:00:00:%02x:%02x:%02x", i>>16&0xff, i>>8&0xff, i&0xff)
+		for v, state := range []string{"in_use", "provisioning"} {
+			js[i][v] = fmt.Sprintf(`{"id":"%08x-0000-0000-0000-000000000000","state":%q,`+
+				`"ip_addresses":[{"address":"10.%d.%d.%d"}],"network_ports":[{"name":"eth0","data":{"mac":%q}}]}`,
+				i, state, i>>16&0xff, i>>8&0xff, i&0xff, macs[i])
+		}
+	}
+
+	return js, macs
+}
+
+// BenchmarkByMACUnderPush looks hardware up by mac address while writers keep pushing changes to other hardware, and
+// reports the 99th percentile latency of the lookups.
+func BenchmarkByMACUnderPush(b *testing.B) {
+	const (
+		n       = 10000
+		writers = 4
+	)
+
+	js, macs := benchHardware(n)
+
+	h := New()
+	for _, j := range js {
+		if _, err := h.Add(j[0]); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	stop := make(chan struct{})
+	wg := sync.WaitGroup{}
+
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+
+		go func(w int) {
+			defer wg.Done()
+
+			for i := w; ; i += writers {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				if _, err := h.Add(js[i%n][i/n%2]); err != nil {
+					panic(err)
+				}
+			}
+		}(w)
+	}
+
+	mu := sync.Mutex{}
+	latencies := make([]time.Duration, 0, b.N)
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		ls := []time.Duration{}
+
+		for i := 0; pb.Next(); i++ {
+			start := time.Now()
+
+			if _, err := h.ByMAC(macs[i*7919%n]); err != nil {
+				panic(err)
+			}
+
+			ls = append(ls, time.Since(start))
+		}
+
+		mu.Lock()
+		latencies = append(latencies, ls...)
+		mu.Unlock()
+	})
+	b.StopTimer()
+
+	close(stop)
+	wg.Wait()
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	b.ReportMetric(float64(latencies[len(latencies)*99/100].Nanoseconds()), "p99-ns/op")
+}
@@ -0,0 +1,186 @@
+package hardware
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Store is a database of hardware documents looked up by id, ip and mac address.
+// Hardware is the in memory Store, which also supports every other lookup, see package bolt for one persisted on disk.
+// The behaviour expected of a Store is checked by package storetest.
+type Store interface {
+	// Add stores the hardware document j, replacing any stored for its id, and returns its id.
+	// Hardware with state == deleted is deleted.
+	Add(j string) (string, error)
+	// Get returns the hardware with the given id, "" if there is none.
+	Get(id string) (string, error)
+	// ByIP returns the hardware with the given ip address, "" if there is none.
+	ByIP(ip string) (string, error)
+	// ByMAC returns the hardware with the given mac address, "" if there is none.
+	ByMAC(mac string) (string, error)
+	// All calls fn with each hardware, ordered by id.
+	All(fn func(string) error) error
+	// Delete deletes the hardware with the given id, if any.
+	Delete(id string) error
+	// Subscribe returns a channel receiving the documents stored for the hardware with the given id, including the ones
+	// deleting it, until cancel is called. Documents are dropped rather than wait for a subscriber not keeping up.
+	Subscribe(id string) (ch <-chan string, cancel func())
+}
+
+// BulkAdder is implemented by the Stores that can store many documents at once more cheaply than one at a time.
+type BulkAdder interface {
+	// AddBulk stores the documents js like Add would and returns the error of each, nil for the ones stored.
+	// It only fails as a whole, storing none of them, if they can't be committed.
+	// It is meant for loading the store, so the documents are not sent to subscribers.
+	AddBulk(js []string) ([]error, error)
+}
+
+// Document is the part of a hardware document a Store indexes, as returned by Parse.
+type Document struct {
+	ID      string
+	Deleted bool     // state == deleted
+	IPs     []string // sorted, in canonical form
+	MACs    []string // sorted, in canonical form
+}
+
+// Parse validates j like Add would and returns what it needs to be indexed by.
+// The addresses of deleted hardware are ignored.
+func Parse(j string) (Document, error) {
+	d, err := (&Hardware{}).parse(j)
+	if err != nil {
+		return Document{}, err
+	}
+
+	doc := Document{ID: d.hw.ID, Deleted: d.hw.State == "deleted"}
+
+	for ip := range d.ips {
+		doc.IPs = append(doc.IPs, ip.String())
+	}
+
+	for mac := range d.macs {
+		doc.MACs = append(doc.MACs, string(mac))
+	}
+
+	sort.Strings(doc.IPs)
+	sort.Strings(doc.MACs)
+
+	return doc, nil
+}
+
+// Deletion returns the document deleting the hardware with the given id.
+func Deletion(id string) (string, error) {
+	b, err := json.Marshal(struct {
+		ID    string `json:"id"`
+		State string `json:"state"`
+	}{ID: id, State: "deleted"})
+	if err != nil {
+		return "", errors.Wrap(err, "unable to encode json")
+	}
+
+	return string(b), nil
+}
+
+// Feed passes documents on to the subscribers of their hardware, for implementing Store.Subscribe.
+// The zero value is ready to use.
+type Feed struct {
+	// Misses, if set, counts the documents dropped because a subscriber wasn't keeping up.
+	Misses prometheus.Counter
+
+	mu   sync.Mutex
+	subs map[string]map[chan string]bool
+}
+
+// Subscribe implements Store.Subscribe, the channel has room for a single document.
+func (f *Feed) Subscribe(id string) (<-chan string, func()) {
+	ch := make(chan string, 1)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.subs == nil {
+		f.subs = map[string]map[chan string]bool{}
+	}
+
+	if f.subs[id] == nil {
+		f.subs[id] = map[chan string]bool{}
+	}
+
+	f.subs[id][ch] = true
+
+	var once sync.Once
+
+	return ch, func() {
+		once.Do(func() {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+
+			delete(f.subs[id], ch)
+			if len(f.subs[id]) == 0 {
+				delete(f.subs, id)
+			}
+
+			close(ch)
+		})
+	}
+}
+
+// Publish sends j to the subscribers of the hardware with the given id without waiting for them.
+func (f *Feed) Publish(id, j string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for ch := range f.subs[id] {
+		select {
+		case ch <- j:
+		default:
+			if f.Misses != nil {
+				f.Misses.Inc()
+			}
+		}
+	}
+}
+
+// WatchMisses will set the counter of documents dropped because a subscriber wasn't keeping up.
+func WatchMisses(c prometheus.Counter) Option {
+	return func(h *Hardware) {
+		h.feed.Misses = c
+	}
+}
+
+// Get implements Store, it is ByID.
+func (h *Hardware) Get(v string) (string, error) {
+	return h.ByID(v)
+}
+
+// Delete implements Store, by storing the document returned by Deletion as pushed, so a tombstone is left if configured
+// with Tombstones.
+func (h *Hardware) Delete(v string) error {
+	j, err := Deletion(v)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = h.Swap(j, FromPush)
+
+	return err
+}
+
+// Subscribe implements Store.
+// Every document stored by Add, Swap, CompareAndSwap, Patch and Batch is published unless it was unchanged, Batch's
+// only once all of them are stored.
+func (h *Hardware) Subscribe(v string) (<-chan string, func()) {
+	return h.feed.Subscribe(v)
+}
+
+// notify passes the stored documents on to their subscribers.
+func (h *Hardware) notify(ws []Written, js []string) {
+	for i, w := range ws {
+		if !w.Unchanged {
+			h.feed.Publish(w.ID, js[i])
+		}
+	}
+}
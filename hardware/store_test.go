@@ -0,0 +1,14 @@
+package hardware_test
+
+import (
+	"testing"
+
+	"github.com/packethost/cacher/hardware"
+	"github.com/packethost/cacher/hardware/storetest"
+)
+
+func TestStore(t *testing.T) {
+	storetest.Run(t, func(*testing.T) hardware.Store {
+		return hardware.New()
+	})
+}
@@ -0,0 +1,292 @@
+// Package storetest checks implementations of hardware.Store behave alike.
+package storetest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/packethost/cacher/hardware"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+var errAbort = errors.New("abort")
+
+const (
+	id1 = "00000000-0000-0000-0000-000000000001"
+	id2 = "00000000-0000-0000-0000-000000000002"
+)
+
+// hw returns a hardware document with the given id, ip and mac address, either of which may be "".
+func hw(id, ip, mac string) string {
+	j := `{"id":"` + id + `"`
+	if ip != "" {
+		j += `,"ip_addresses":[{"address":"` + ip + `"}]`
+	}
+
+	if mac != "" {
+		j += `,"network_ports":[{"name":"eth0","data":{"mac":"` + mac + `"}}]`
+	}
+
+	return j + `}`
+}
+
+// all returns every hardware of s.
+func all(t *testing.T, s hardware.Store) []string {
+	js := []string{}
+	require.NoError(t, s.All(func(j string) error {
+		js = append(js, j)
+
+		return nil
+	}))
+
+	return js
+}
+
+// Run checks the stores returned by newStore, a new empty one for each subtest, behave like a hardware.Store.
+func Run(t *testing.T, newStore func(t *testing.T) hardware.Store) {
+	t.Run("Add", func(t *testing.T) {
+		assert := require.New(t)
+		s := newStore(t)
+
+		j := hw(id1, "10.0.0.1", "00:00:00:00:00:0a")
+		id, err := s.Add(j)
+		assert.NoError(err)
+		assert.Equal(id1, id)
+
+		got, err := s.Get(id1)
+		assert.NoError(err)
+		assert.Equal(j, got)
+
+		got, err = s.ByIP("10.0.0.1")
+		assert.NoError(err)
+		assert.Equal(j, got)
+
+		got, err = s.ByMAC("00:00:00:00:00:0a")
+		assert.NoError(err)
+		assert.Equal(j, got)
+
+		// lookups are insensitive to case and spacing like the in memory store's
+		got, err = s.Get(" " + id1 + " ")
+		assert.NoError(err)
+		assert.Equal(j, got)
+
+		got, err = s.ByMAC("00:00:00:00:00:0A")
+		assert.NoError(err)
+		assert.Equal(j, got)
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		assert := require.New(t)
+		s := newStore(t)
+
+		j, err := s.Get(id1)
+		assert.NoError(err)
+		assert.Empty(j)
+
+		j, err = s.ByIP("10.0.0.1")
+		assert.NoError(err)
+		assert.Empty(j)
+
+		j, err = s.ByMAC("00:00:00:00:00:01")
+		assert.NoError(err)
+		assert.Empty(j)
+
+		assert.Empty(all(t, s))
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		assert := require.New(t)
+		s := newStore(t)
+
+		for _, j := range []string{
+			`not json`,
+			`{"id":"not a uuid"}`,
+			hw(id1, "not an ip", ""),
+			hw(id1, "", "not a mac"),
+		} {
+			_, err := s.Add(j)
+			assert.Error(err, j)
+		}
+
+		assert.Empty(all(t, s))
+
+		_, err := s.ByIP("not an ip")
+		assert.Error(err)
+
+		_, err = s.ByMAC("not a mac")
+		assert.Error(err)
+	})
+
+	t.Run("Replace", func(t *testing.T) {
+		assert := require.New(t)
+		s := newStore(t)
+
+		_, err := s.Add(hw(id1, "10.0.0.1", "00:00:00:00:00:01"))
+		assert.NoError(err)
+
+		j := hw(id1, "10.0.0.2", "00:00:00:00:00:02")
+		_, err = s.Add(j)
+		assert.NoError(err)
+
+		got, err := s.ByIP("10.0.0.1")
+		assert.NoError(err)
+		assert.Empty(got)
+
+		got, err = s.ByMAC("00:00:00:00:00:01")
+		assert.NoError(err)
+		assert.Empty(got)
+
+		got, err = s.ByIP("10.0.0.2")
+		assert.NoError(err)
+		assert.Equal(j, got)
+
+		assert.Equal([]string{j}, all(t, s))
+	})
+
+	t.Run("SharedAddress", func(t *testing.T) {
+		assert := require.New(t)
+		s := newStore(t)
+
+		j1 := hw(id1, "10.0.0.1", "")
+		_, err := s.Add(j1)
+		assert.NoError(err)
+
+		j2 := hw(id2, "10.0.0.1", "")
+		_, err = s.Add(j2)
+		assert.NoError(err)
+
+		// the last one stored wins
+		got, err := s.ByIP("10.0.0.1")
+		assert.NoError(err)
+		assert.Equal(j2, got)
+
+		// moving the address off the other hardware doesn't lose it
+		_, err = s.Add(hw(id1, "", ""))
+		assert.NoError(err)
+
+		got, err = s.ByIP("10.0.0.1")
+		assert.NoError(err)
+		assert.Equal(j2, got)
+	})
+
+	t.Run("All", func(t *testing.T) {
+		assert := require.New(t)
+		s := newStore(t)
+
+		j1, j2 := hw(id1, "", ""), hw(id2, "", "")
+		for _, j := range []string{j2, j1} {
+			_, err := s.Add(j)
+			assert.NoError(err)
+		}
+
+		assert.Equal([]string{j1, j2}, all(t, s))
+
+		n := 0
+		err := s.All(func(string) error {
+			n++
+
+			return errAbort
+		})
+		assert.ErrorIs(err, errAbort)
+		assert.Equal(1, n)
+	})
+
+	t.Run("AddBulk", func(t *testing.T) {
+		assert := require.New(t)
+		s := newStore(t)
+
+		b, ok := s.(hardware.BulkAdder)
+		if !ok {
+			t.Skip("not a hardware.BulkAdder")
+		}
+
+		_, err := s.Add(hw(id2, "10.0.0.2", ""))
+		assert.NoError(err)
+
+		j1 := hw(id1, "10.0.0.1", "")
+		errs, err := b.AddBulk([]string{j1, `not json`, `{"id":"` + id2 + `","state":"deleted"}`})
+		assert.NoError(err)
+		assert.Len(errs, 3)
+		assert.NoError(errs[0])
+		assert.Error(errs[1])
+		assert.NoError(errs[2])
+
+		assert.Equal([]string{j1}, all(t, s))
+
+		got, err := s.ByIP("10.0.0.2")
+		assert.NoError(err)
+		assert.Empty(got)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		assert := require.New(t)
+		s := newStore(t)
+
+		_, err := s.Add(hw(id1, "10.0.0.1", "00:00:00:00:00:01"))
+		assert.NoError(err)
+
+		j2 := hw(id2, "10.0.0.2", "")
+		_, err = s.Add(j2)
+		assert.NoError(err)
+
+		assert.NoError(s.Delete(id1))
+
+		got, err := s.Get(id1)
+		assert.NoError(err)
+		assert.Empty(got)
+
+		got, err = s.ByIP("10.0.0.1")
+		assert.NoError(err)
+		assert.Empty(got)
+
+		got, err = s.ByMAC("00:00:00:00:00:01")
+		assert.NoError(err)
+		assert.Empty(got)
+
+		assert.Equal([]string{j2}, all(t, s))
+
+		// deleting hardware that doesn't exist is not an error
+		assert.NoError(s.Delete(id1))
+
+		_, err = s.Add(`{"id":"` + id2 + `","state":"deleted"}`)
+		assert.NoError(err)
+		assert.Empty(all(t, s))
+	})
+
+	t.Run("Subscribe", func(t *testing.T) {
+		assert := require.New(t)
+		s := newStore(t)
+
+		ch, cancel := s.Subscribe(id1)
+
+		j := hw(id1, "", "")
+		_, err := s.Add(j)
+		assert.NoError(err)
+		assert.Equal(j, <-ch)
+
+		_, err = s.Add(hw(id2, "", ""))
+		assert.NoError(err)
+		assert.Empty(ch)
+
+		assert.NoError(s.Delete(id1))
+		assert.Contains(<-ch, `"deleted"`)
+
+		// documents are dropped instead of waiting for a subscriber whose channel is full
+		for i := 0; i < 3; i++ {
+			_, err = s.Add(hw(id1, fmt.Sprintf("10.0.0.%d", i), ""))
+			assert.NoError(err)
+		}
+
+		<-ch
+
+		cancel()
+		cancel()
+
+		_, ok := <-ch
+		assert.False(ok)
+
+		_, err = s.Add(j)
+		assert.NoError(err)
+	})
+}
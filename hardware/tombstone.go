@@ -0,0 +1,115 @@
+package hardware
+
+import (
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Tombstones will keep hardware pushed with state == deleted around for ttl, so ByIDStatus can tell it was deleted and
+// when. Tombstones are not returned by the other lookups or All, Sweep removes them once expired.
+// Deleted hardware is forgotten immediately if ttl is 0, the default.
+func Tombstones(ttl time.Duration) Option {
+	return func(h *Hardware) {
+		h.ttl = ttl
+	}
+}
+
+// TombstoneGauge will set the gauge used to track the number of tombstones.
+func TombstoneGauge(g prometheus.Gauge) Option {
+	return func(h *Hardware) {
+		h.tombstoneGauge = g
+	}
+}
+
+// ByIDStatus returns the hardware with the given id along with its revision and when it was deleted, the zero time if
+// it was not. Deleted hardware is returned as it was pushed to delete it.
+func (h *Hardware) ByIDStatus(v string) (string, uint64, time.Time, error) {
+	k := id(strings.TrimSpace(strings.ToLower(v)))
+	s := h.snap.Load()
+	hw, _ := s.hw.get(hashID(k), k)
+
+	j, err := s.codec.decode(hw.j)
+
+	return j, hw.rev, hw.deleted, err
+}
+
+// Sweep removes the tombstones, and history, of hardware deleted more than the tombstone ttl before now, returning how
+// many.
+func (h *Hardware) Sweep(now time.Time) int {
+	h.mu.Lock()
+	n := h.sweep(now)
+	seq := h.seq
+	h.mu.Unlock()
+
+	h.publish(seq)
+
+	return n
+}
+
+// sweep is Sweep, must be called with the lock held.
+func (h *Hardware) sweep(now time.Time) int {
+	n := 0
+
+	for v, deleted := range h.tombstones {
+		if now.Sub(deleted) <= h.ttl {
+			continue
+		}
+
+		delete(h.tombstones, v)
+		h.countBytes(h.hw[v].j, h.hw[v].size, -1)
+		h.lastRevs[v] = h.hw[v].rev
+		delete(h.hw, v)
+		h.forget(v)
+		h.removeID(v)
+		h.touch(v)
+		n++
+	}
+
+	if h.tombstoneGauge != nil {
+		h.tombstoneGauge.Sub(float64(n))
+	}
+
+	return n
+}
+
+// bury replaces v with a tombstone of d at revision rev, deleted at now.
+// Must be called with the lock held, after v's addresses and index entries were released.
+func (h *Hardware) bury(v id, d *doc, rev uint64, now time.Time) {
+	og, ok := h.hw[v]
+	_, dead := h.tombstones[v]
+
+	og.j = d.stored
+	og.size = len(d.j)
+	og.sum = d.sum
+	og.ips = nil
+	og.macs = nil
+	og.ports = nil
+	og.vals = nil
+	og.rev = rev
+	h.hw[v] = og
+	h.tombstones[v] = now
+
+	if !ok {
+		h.insertID(v)
+	}
+
+	if !dead && h.tombstoneGauge != nil {
+		h.tombstoneGauge.Inc()
+	}
+}
+
+// revive removes the tombstone of v, if any.
+// Must be called with the lock held.
+func (h *Hardware) revive(v id) {
+	if _, dead := h.tombstones[v]; !dead {
+		return
+	}
+
+	delete(h.tombstones, v)
+
+	if h.tombstoneGauge != nil {
+		h.tombstoneGauge.Dec()
+	}
+}
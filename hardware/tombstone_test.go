@@ -0,0 +1,113 @@
+package hardware
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTombstones(t *testing.T) {
+	assert := require.New(t)
+
+	const (
+		id1 = "00000000-0000-0000-0000-000000000001"
+		id2 = "00000000-0000-0000-0000-000000000002"
+	)
+
+	count := prometheus.NewGauge(prometheus.GaugeOpts{Name: "count"})
+	tombstones := prometheus.NewGauge(prometheus.GaugeOpts{Name: "tombstones"})
+	hw := New(Gauge(count), Tombstones(time.Hour), TombstoneGauge(tombstones))
+
+	for _, j := range []string{
+		conflictHW(id1, "10.0.0.1", "00:00:00:00:00:01"),
+		conflictHW(id2, "10.0.0.2", "00:00:00:00:00:02"),
+	} {
+		_, err := hw.Add(j)
+		assert.NoError(err)
+	}
+
+	deleted := `{"id":"` + id1 + `","state":"deleted"}`
+	before := time.Now()
+	_, err := hw.Add(deleted)
+	assert.NoError(err)
+
+	assert.Equal(1.0, testutil.ToFloat64(count))
+	assert.Equal(1.0, testutil.ToFloat64(tombstones))
+
+	j, err := hw.ByID(id1)
+	assert.NoError(err)
+	assert.Empty(j)
+
+	j, rev, at, err := hw.ByIDStatus(id1)
+	assert.NoError(err)
+	assert.Equal(deleted, j)
+	assert.Equal(uint64(2), rev)
+	assert.False(at.Before(before))
+
+	j, rev, at, err = hw.ByIDStatus(id2)
+	assert.NoError(err)
+	assert.Contains(j, id2)
+	assert.Equal(uint64(1), rev)
+	assert.True(at.IsZero())
+
+	j, err = hw.ByIP("10.0.0.1")
+	assert.NoError(err)
+	assert.Empty(j)
+
+	j, err = hw.ByMAC("00:00:00:00:00:01")
+	assert.NoError(err)
+	assert.Empty(j)
+
+	var got []string
+	collect := func(j string) error {
+		got = append(got, j)
+
+		return nil
+	}
+
+	assert.NoError(hw.All(collect))
+	assert.Len(got, 1)
+	assert.Contains(got[0], id2)
+
+	got = nil
+	last, more, err := hw.Page("", 1, true, collect)
+	assert.NoError(err)
+	assert.True(more)
+	assert.Equal(id1, last)
+	assert.Equal([]string{deleted}, got)
+
+	// deleting again keeps the tombstone
+	_, err = hw.Add(deleted)
+	assert.NoError(err)
+	assert.Equal(1.0, testutil.ToFloat64(count))
+	assert.Equal(1.0, testutil.ToFloat64(tombstones))
+
+	assert.Zero(hw.Sweep(time.Now()))
+	assert.Equal(1, hw.Sweep(time.Now().Add(2*time.Hour)))
+	assert.Equal(0.0, testutil.ToFloat64(tombstones))
+
+	j, _, at, err = hw.ByIDStatus(id1)
+	assert.NoError(err)
+	assert.Empty(j)
+	assert.True(at.IsZero())
+
+	got = nil
+	_, _, err = hw.Page("", 0, true, collect)
+	assert.NoError(err)
+	assert.Len(got, 1)
+
+	// pushing deleted hardware again brings it back
+	_, err = hw.Add(`{"id":"` + id2 + `","state":"deleted"}`)
+	assert.NoError(err)
+	_, err = hw.Add(conflictHW(id2, "10.0.0.2", "00:00:00:00:00:02"))
+	assert.NoError(err)
+	assert.Equal(1.0, testutil.ToFloat64(count))
+	assert.Equal(0.0, testutil.ToFloat64(tombstones))
+
+	j, err = hw.ByIP("10.0.0.2")
+	assert.NoError(err)
+	assert.Contains(j, id2)
+}
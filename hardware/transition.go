@@ -0,0 +1,184 @@
+package hardware
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// StateGraph is the states hardware is allowed to move to from each state.
+// Staying in the same state, moving to deleted and moving out of no state at all are always allowed.
+type StateGraph map[string][]string
+
+// DefaultStateGraph returns the lifecycle of hardware as seen by boots: it is burned in and preinstalled once enrolled,
+// then goes back and forth between provisionable and in_use.
+func DefaultStateGraph() StateGraph {
+	return StateGraph{
+		"enrolled":          {"burn_in", "preinstallable"},
+		"burn_in":           {"preinstallable"},
+		"preinstallable":    {"preinstalling"},
+		"preinstalling":     {"provisionable", "failed_preinstall"},
+		"failed_preinstall": {"preinstallable", "preinstalling"},
+		"provisionable":     {"provisioning", "preinstallable"},
+		"provisioning":      {"in_use", "provisionable"},
+		"in_use":            {"deprovisioning"},
+		"deprovisioning":    {"provisionable"},
+	}
+}
+
+// Validate checks the states of g are named and not deleted.
+func (g StateGraph) Validate() error {
+	for from, tos := range g {
+		if from == "" || from == "deleted" {
+			return errors.Errorf("invalid state %q", from)
+		}
+
+		for _, to := range tos {
+			if to == "" || to == "deleted" {
+				return errors.Errorf("invalid state %q to go to from %q", to, from)
+			}
+		}
+	}
+
+	return nil
+}
+
+// States returns every state of g, sorted.
+func (g StateGraph) States() []string {
+	seen := map[string]bool{}
+	for from, tos := range g {
+		seen[from] = true
+		for _, to := range tos {
+			seen[to] = true
+		}
+	}
+
+	states := make([]string, 0, len(seen))
+	for s := range seen {
+		states = append(states, s)
+	}
+
+	sort.Strings(states)
+
+	return states
+}
+
+// Allowed reports whether hardware may move from one state to the other.
+func (g StateGraph) Allowed(from, to string) bool {
+	if from == to || from == "" || to == "deleted" {
+		return true
+	}
+
+	for _, s := range g[from] {
+		if s == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// known returns s if it is a state of g, "other" otherwise, to keep the cardinality of the transition metrics bounded.
+func (g StateGraph) known(s string) string {
+	if _, ok := g[s]; ok {
+		return s
+	}
+
+	for _, tos := range g {
+		for _, to := range tos {
+			if to == s {
+				return s
+			}
+		}
+	}
+
+	return "other"
+}
+
+// TransitionPolicy decides what happens to pushes moving hardware to a state it isn't allowed to go to.
+type TransitionPolicy string
+
+const (
+	// TransitionsAllow doesn't check transitions.
+	TransitionsAllow TransitionPolicy = "allow"
+	// TransitionsWarn logs and counts illegal transitions, but stores them.
+	TransitionsWarn TransitionPolicy = "warn"
+	// TransitionsReject fails pushes with illegal transitions with ErrIllegalTransition.
+	TransitionsReject TransitionPolicy = "reject"
+)
+
+// ParseTransitionPolicy returns the policy named s.
+func ParseTransitionPolicy(s string) (TransitionPolicy, error) {
+	switch p := TransitionPolicy(s); p {
+	case TransitionsAllow, TransitionsWarn, TransitionsReject:
+		return p, nil
+	}
+
+	return "", errors.Errorf("unknown transition policy %q", s)
+}
+
+// ErrIllegalTransition is returned (wrapped) for pushes moving hardware to a state it isn't allowed to go to when the
+// policy is TransitionsReject.
+var ErrIllegalTransition = errors.New("illegal state transition")
+
+// Transitions will check the state changes of pushed hardware against g, according to p.
+// Ingested hardware, and hardware that isn't stored yet or was deleted, can be in any state.
+func Transitions(g StateGraph, p TransitionPolicy) Option {
+	return func(h *Hardware) {
+		h.graph = g
+		h.transitions = p
+	}
+}
+
+// TransitionCounter will set the counter of illegal transitions, labeled by from and to state (other for the states
+// not in the graph) and policy.
+func TransitionCounter(c *prometheus.CounterVec) Option {
+	return func(h *Hardware) {
+		h.illegalTransitions = c
+	}
+}
+
+// StateGraph returns the graph the transitions of pushed hardware are checked against, and the policy applied.
+func (h *Hardware) StateGraph() (StateGraph, TransitionPolicy) {
+	return h.graph, h.transitions
+}
+
+// checkPushedState checks the transition to the state of d if it is pushed for hardware that is stored and not deleted.
+// Must be called with the lock held.
+func (h *Hardware) checkPushedState(d *doc, source string) error {
+	v := d.id()
+
+	og, ok := h.hw[v]
+	if _, dead := h.tombstones[v]; !ok || dead || source != FromPush {
+		return nil
+	}
+
+	return h.checkTransition(v, og.state, d.hw.State)
+}
+
+// checkTransition checks the hardware with id v may move between the states.
+func (h *Hardware) checkTransition(v id, from, to string) error {
+	if h.transitions == TransitionsAllow || h.graph.Allowed(from, to) {
+		return nil
+	}
+
+	if h.illegalTransitions != nil {
+		h.illegalTransitions.With(prometheus.Labels{
+			"from":   h.graph.known(from),
+			"to":     h.graph.known(to),
+			"policy": string(h.transitions),
+		}).Inc()
+	}
+
+	err := errors.Wrapf(ErrIllegalTransition, "%s can't go from %q to %q", v, from, to)
+	if h.transitions == TransitionsReject {
+		return err
+	}
+
+	if h.logger != nil {
+		h.logger.With("id", v, "from", from, "to", to).Error(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package hardware
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func stateHW(state string) string {
+	return `{"id":"5ed553c8-8eab-496a-bbf0-73ce1f900390","state":"` + state + `"}`
+}
+
+func TestTransitions(t *testing.T) {
+	assert := require.New(t)
+
+	illegal := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "illegal"}, []string{"from", "to", "policy"})
+	h := New(Transitions(DefaultStateGraph(), TransitionsReject), TransitionCounter(illegal), Tombstones(time.Hour))
+
+	// new hardware can be in any state
+	_, _, err := h.Swap(stateHW("in_use"), FromPush)
+	assert.NoError(err)
+
+	_, _, err = h.Swap(stateHW("provisionable"), FromPush)
+	assert.ErrorIs(err, ErrIllegalTransition)
+	assert.Contains(err.Error(), `from "in_use" to "provisionable"`)
+	assert.Equal(1.0, testutil.ToFloat64(illegal.WithLabelValues("in_use", "provisionable", "reject")))
+
+	got, err := h.ByID("5ed553c8-8eab-496a-bbf0-73ce1f900390")
+	assert.NoError(err)
+	assert.Equal(stateHW("in_use"), got)
+
+	_, _, err = h.Swap(stateHW("in-use"), FromPush)
+	assert.ErrorIs(err, ErrIllegalTransition)
+	assert.Equal(1.0, testutil.ToFloat64(illegal.WithLabelValues("in_use", "other", "reject")))
+
+	for _, state := range []string{"in_use", "deprovisioning", "provisionable", "provisioning"} {
+		_, _, err = h.Swap(stateHW(state), FromPush)
+		assert.NoError(err, state)
+	}
+
+	// ingested hardware isn't checked
+	_, err = h.Add(stateHW("enrolled"))
+	assert.NoError(err)
+
+	// deleted hardware can come back in any state
+	_, _, err = h.Swap(stateHW("deleted"), FromPush)
+	assert.NoError(err)
+
+	_, _, err = h.Swap(stateHW("in_use"), FromPush)
+	assert.NoError(err)
+
+	// patches are pushes
+	_, _, err = h.Patch("5ed553c8-8eab-496a-bbf0-73ce1f900390", MergePatch, `{"state":"enrolled"}`, FromPush, 0)
+	assert.ErrorIs(err, ErrIllegalTransition)
+}
+
+func TestTransitionsWarn(t *testing.T) {
+	assert := require.New(t)
+
+	illegal := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "illegal"}, []string{"from", "to", "policy"})
+	h := New(Transitions(DefaultStateGraph(), TransitionsWarn), TransitionCounter(illegal))
+
+	for _, state := range []string{"in_use", "provisionable"} {
+		_, _, err := h.Swap(stateHW(state), FromPush)
+		assert.NoError(err)
+	}
+
+	got, err := h.ByID("5ed553c8-8eab-496a-bbf0-73ce1f900390")
+	assert.NoError(err)
+	assert.Equal(stateHW("provisionable"), got)
+	assert.Equal(1.0, testutil.ToFloat64(illegal.WithLabelValues("in_use", "provisionable", "warn")))
+
+	// not checked by default
+	h = New()
+	for _, state := range []string{"in_use", "provisionable"} {
+		_, _, err := h.Swap(stateHW(state), FromPush)
+		assert.NoError(err)
+	}
+}
+
+func TestStateGraph(t *testing.T) {
+	assert := require.New(t)
+
+	g := DefaultStateGraph()
+	assert.NoError(g.Validate())
+	assert.Contains(g.States(), "failed_preinstall")
+	assert.True(g.Allowed("in_use", "in_use"))
+	assert.True(g.Allowed("in_use", "deleted"))
+	assert.True(g.Allowed("", "in_use"))
+	assert.False(g.Allowed("in_use", "provisionable"))
+
+	assert.Error(StateGraph{"in_use": {""}}.Validate())
+	assert.Error(StateGraph{"deleted": {"in_use"}}.Validate())
+
+	_, err := ParseTransitionPolicy("strict")
+	assert.Error(err)
+}
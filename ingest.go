@@ -130,7 +130,7 @@ func fetchFacility(ctx context.Context, client *packngo.Client, api *url.URL, fa
 	return nil
 }
 
-func copyin(hw *hardware.Hardware, data <-chan []map[string]interface{}) error {
+func copyin(hw hardware.Store, data <-chan []map[string]interface{}) error {
 	for hws := range data {
 		if err := copyInEach(hw, hws); err != nil {
 			return err
@@ -140,7 +140,7 @@ func copyin(hw *hardware.Hardware, data <-chan []map[string]interface{}) error {
 	return nil
 }
 
-func copyInEach(hw *hardware.Hardware, data []map[string]interface{}) error {
+func copyInEach(hw hardware.Store, data []map[string]interface{}) error {
 	logger.Info("copy start")
 	labels := prometheus.Labels{"method": "Ingest", "op": "copy"}
 	ingestCount.With(labels).Inc()
@@ -148,19 +148,36 @@ func copyInEach(hw *hardware.Hardware, data []map[string]interface{}) error {
 
 	now := time.Now()
 
-	for _, j := range data {
-		var q []byte
-
+	js := make([]string, len(data))
+	for i, j := range data {
 		q, err := json.Marshal(j)
 		if err != nil {
 			return errors.Wrap(err, "marshal json")
 		}
 
-		_, err = hw.Add(string(q))
+		js[i] = string(q)
+	}
+
+	// the page is stored at once rather than sync or lock for each document, and isn't sent to watchers like the
+	// pushed hardware is
+	if b, ok := hw.(hardware.BulkAdder); ok {
+		errs, err := b.AddBulk(js)
 		if err != nil {
-			logger.With("json", string(q)).Error(err)
 			return err
 		}
+
+		for i, err := range errs {
+			if err := copyError(js[i], err); err != nil {
+				return err
+			}
+		}
+	} else {
+		for _, j := range js {
+			_, err := hw.Add(j)
+			if err := copyError(j, err); err != nil {
+				return err
+			}
+		}
 	}
 
 	timer.ObserveDuration()
@@ -169,6 +186,24 @@ func copyInEach(hw *hardware.Hardware, data []map[string]interface{}) error {
 	return nil
 }
 
+// copyError returns the error storing j failed with, if it is worth failing the ingestion for.
+func copyError(j string, err error) error {
+	if errors.Is(err, hardware.ErrConflict) {
+		// already logged, the rest of the facility is still worth having
+		return nil
+	}
+
+	if err != nil {
+		logger.With("json", j).Error(err)
+	}
+
+	if errors.Is(err, hardware.ErrSchema) {
+		return nil
+	}
+
+	return err
+}
+
 func (s *server) ingest(ctx context.Context, api *url.URL, facility string) error { //nolint:nolintlint,revive
 	if env.Bool("CACHER_NO_INGEST") {
 		cacherState.Set(2)
@@ -218,7 +253,7 @@ func (s *server) ingest(ctx context.Context, api *url.URL, facility string) erro
 	go func() {
 		defer wg.Done()
 
-		if err := copyin(s.hw, ch); err != nil {
+		if err := copyin(s.store, ch); err != nil {
 			labels := prometheus.Labels{"method": "Ingest", "op": "copy"}
 			ingestErrors.With(labels).Inc()
 
@@ -244,9 +279,29 @@ func (s *server) ingest(ctx context.Context, api *url.URL, facility string) erro
 	default:
 	}
 
+	s.retrain()
+
 	s.ingestReadyLock.Lock()
 	s.ingestDone = true
 	s.ingestReadyLock.Unlock()
 
 	return nil
 }
+
+// retrain retrains the compression dictionary on the ingested hardware, if compressing.
+func (s *server) retrain() {
+	hw, ok := s.store.(*hardware.Hardware)
+	if !ok || s.dictSize <= 0 {
+		return
+	}
+
+	now := time.Now()
+	if err := hw.Retrain(s.dictSize); err != nil {
+		logger.Error(errors.Wrap(err, "retrain compression dictionary"))
+
+		return
+	}
+
+	raw, stored := hw.DocumentBytes()
+	logger.With("duration", time.Since(now), "raw", raw, "stored", stored).Info("retrained compression dictionary")
+}
@@ -10,11 +10,14 @@ import (
 	"os"
 	"os/signal"
 	"runtime"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/equinix-labs/otel-init-go/otelinit"
 	"github.com/packethost/cacher/hardware"
+	"github.com/packethost/cacher/hardware/bolt"
 	"github.com/packethost/cacher/pkg/healthcheck"
 	"github.com/packethost/cacher/protos/cacher"
 	"github.com/packethost/packngo"
@@ -22,10 +25,15 @@ import (
 	"github.com/packethost/pkg/grpc"
 	"github.com/packethost/pkg/log"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
-
+	ggrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
 var (
@@ -45,22 +53,144 @@ func mustParseURL(s string) *url.URL {
 	return u
 }
 
-func setupGRPC(ctx context.Context, client *packngo.Client, errCh chan<- error) *server {
+func setupGRPC(ctx context.Context, client *packngo.Client, opts []grpc.Option, errCh chan<- error) *server {
 	cert := []byte(env.Get("CACHER_TLS_CERT"))
 
+	var indexes []hardware.IndexSpec
+	if v := env.Get("CACHER_INDEXES"); v != "" {
+		if err := json.Unmarshal([]byte(v), &indexes); err != nil {
+			logger.Fatal(errors.Wrap(err, "decode CACHER_INDEXES"))
+		}
+
+		if err := hardware.ValidateIndexSpecs(indexes); err != nil {
+			logger.Fatal(errors.Wrap(err, "validate CACHER_INDEXES"))
+		}
+	}
+
+	policy, err := hardware.ParseConflictPolicy(env.Get("CACHER_CONFLICT_POLICY", string(hardware.LastWriteWins)))
+	if err != nil {
+		logger.Fatal(errors.Wrap(err, "parse CACHER_CONFLICT_POLICY"))
+	}
+
+	mode, err := hardware.ParseSchemaMode(env.Get("CACHER_SCHEMA_MODE", string(hardware.SchemaWarn)))
+	if err != nil {
+		logger.Fatal(errors.Wrap(err, "parse CACHER_SCHEMA_MODE"))
+	}
+
+	validator, err := hardware.NewValidator(env.Get("CACHER_SCHEMA_VERSION", hardware.LatestSchema), mode)
+	if err != nil {
+		logger.Fatal(errors.Wrap(err, "setup CACHER_SCHEMA_VERSION"))
+	}
+
+	schemaLogger := logger.Package("hardware")
+	validator.Logger = &schemaLogger
+	validator.Violations = schemaViolations
+
+	graph := hardware.DefaultStateGraph()
+	if v := env.Get("CACHER_STATE_GRAPH"); v != "" {
+		graph = hardware.StateGraph{}
+		if err := json.Unmarshal([]byte(v), &graph); err != nil {
+			logger.Fatal(errors.Wrap(err, "decode CACHER_STATE_GRAPH"))
+		}
+
+		if err := graph.Validate(); err != nil {
+			logger.Fatal(errors.Wrap(err, "validate CACHER_STATE_GRAPH"))
+		}
+	}
+
+	transitions, err := hardware.ParseTransitionPolicy(env.Get("CACHER_TRANSITION_POLICY", string(hardware.TransitionsWarn)))
+	if err != nil {
+		logger.Fatal(errors.Wrap(err, "parse CACHER_TRANSITION_POLICY"))
+	}
+
+	var (
+		store    hardware.Store
+		dictSize int
+	)
+
+	switch kind := env.Get("CACHER_STORE", "memory"); kind {
+	case "memory":
+		hwOpts := []hardware.Option{
+			hardware.Gauge(cacheCountTotal),
+			hardware.Logger(logger.Package("hardware")),
+			hardware.Indexes(indexes...),
+			hardware.OnConflict(policy),
+			hardware.ConflictGauge(conflicts),
+			hardware.Tombstones(env.Duration("CACHER_TOMBSTONE_TTL", 0)),
+			hardware.TombstoneGauge(tombstones),
+			hardware.Revisions(env.Int("CACHER_HISTORY_SIZE", 5)),
+			hardware.WatchMisses(watchMissTotal),
+			hardware.Schema(validator),
+			hardware.Transitions(graph, transitions),
+			hardware.TransitionCounter(illegalTransitions),
+		}
+
+		if env.Bool("CACHER_COMPRESSION") {
+			hwOpts = append(hwOpts, hardware.Compression(nil), hardware.CompressionSeconds(compressionSeconds))
+			dictSize = env.Int("CACHER_COMPRESSION_DICT_SIZE", hardware.DefaultDictionarySize)
+		}
+
+		hw := hardware.New(hwOpts...)
+		setupDocumentMetrics(hw)
+
+		go sweepTombstones(ctx, hw, env.Duration("CACHER_TOMBSTONE_SWEEP_INTERVAL", time.Minute))
+
+		if interval := env.Duration("CACHER_FSCK_INTERVAL", time.Hour); interval > 0 {
+			go checkIndexes(ctx, hw, interval, env.Bool("CACHER_FSCK_REPAIR"))
+		}
+
+		store = hw
+	case "bolt":
+		if set := memoryOnlySettings(); len(set) > 0 {
+			logger.Fatal(errors.Errorf("%s only apply to the memory store, unset them to use bolt", strings.Join(set, ", ")))
+		}
+
+		db, err := bolt.Open(env.Get("CACHER_STORE_PATH", "cacher.db"), bolt.Gauge(cacheCountTotal), bolt.WatchMisses(watchMissTotal), bolt.Schema(validator))
+		if err != nil {
+			logger.Fatal(errors.Wrap(err, "open CACHER_STORE_PATH"))
+		}
+
+		go func() {
+			<-ctx.Done()
+
+			if err := db.Close(); err != nil {
+				logger.Error(err)
+			}
+		}()
+
+		store = db
+	default:
+		logger.Fatal(errors.Errorf("unknown CACHER_STORE %q, want memory or bolt", kind))
+	}
+
 	server := &server{
 		cert:   cert,
 		modT:   StartTime,
 		packet: client,
 		quit:   ctx.Done(),
-		hw:     hardware.New(hardware.Gauge(cacheCountTotal), hardware.Logger(logger.Package("hardware"))),
-		watch:  map[string]chan string{},
+		store:  store,
+		watch:  map[string]chan struct{}{},
+
+		dictSize: dictSize,
+
+		filterLimits: filterLimitsFromEnv(),
+	}
+
+	if f := env.Get("CACHER_AUDIT_LOG"); f != "" {
+		maxSize := int64(env.Int("CACHER_AUDIT_LOG_MAX_SIZE_MB", 100)) << 20
+
+		l, err := newAuditLog(f, maxSize, env.Int("CACHER_AUDIT_LOG_MAX_BACKUPS", 5))
+		if err != nil {
+			logger.Fatal(errors.Wrap(err, "setup audit log"))
+		}
+
+		server.auditLog = l
 	}
 
 	s, err := grpc.NewServer(logger, func(s *grpc.Server) {
 		cacher.RegisterCacherServer(s.Server(), server)
 		grpc_health_v1.RegisterHealthServer(s.Server(), healthcheck.GRPCHealthChecker())
-	})
+	}, opts...)
 	if err != nil {
 		logger.Fatal(errors.Wrap(err, "setup grpc server"))
 	}
@@ -73,11 +203,131 @@ func setupGRPC(ctx context.Context, client *packngo.Client, errCh chan<- error)
 	go func() {
 		<-ctx.Done()
 		s.Server().GracefulStop()
+
+		if server.auditLog != nil {
+			if err := server.auditLog.Close(); err != nil {
+				logger.Error(errors.Wrap(err, "close audit log"))
+			}
+		}
 	}()
 
 	return server
 }
 
+// memoryOnly are the settings of the features only the memory store has.
+var memoryOnly = []string{
+	"CACHER_COMPRESSION",
+	"CACHER_COMPRESSION_DICT_SIZE",
+	"CACHER_CONFLICT_POLICY",
+	"CACHER_FSCK_INTERVAL",
+	"CACHER_FSCK_REPAIR",
+	"CACHER_HISTORY_SIZE",
+	"CACHER_INDEXES",
+	"CACHER_STATE_GRAPH",
+	"CACHER_TOMBSTONE_SWEEP_INTERVAL",
+	"CACHER_TOMBSTONE_TTL",
+	"CACHER_TRANSITION_POLICY",
+}
+
+// memoryOnlySettings returns the memoryOnly settings set in the environment, which other stores refuse to start with
+// rather than ignore them.
+func memoryOnlySettings() []string {
+	var set []string
+
+	for _, k := range memoryOnly {
+		if _, ok := os.LookupEnv(k); ok {
+			set = append(set, k)
+		}
+	}
+
+	return set
+}
+
+// storeName returns the CACHER_STORE value selecting store.
+func storeName(store hardware.Store) string {
+	switch store.(type) {
+	case *hardware.Hardware:
+		return "memory"
+	case *bolt.Store:
+		return "bolt"
+	}
+
+	return fmt.Sprintf("%T", store)
+}
+
+// sweepTombstones removes expired tombstones from hw every interval until ctx is done.
+func sweepTombstones(ctx context.Context, hw *hardware.Hardware, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-t.C:
+			if n := hw.Sweep(now); n > 0 {
+				tombstonesSwept.Add(float64(n))
+				logger.With("count", n).Info("swept tombstones")
+			}
+		}
+	}
+}
+
+// setupAuth configures client authentication and authorization from the environment.
+// It returns a nil authz and no options if neither mTLS nor bearer tokens are enabled.
+func setupAuth(ctx context.Context) (*authz, []grpc.Option, error) {
+	a := &authz{}
+
+	var opts []grpc.Option
+
+	if ca := env.Get("CACHER_TLS_CLIENT_CA"); ca != "" {
+		certPEM := []byte(env.Get("GRPC_CERT"))
+		keyPEM := []byte(env.Get("GRPC_KEY"))
+
+		// packethost/pkg/grpc appends its own server-only creds when these are set, which would override ours
+		os.Unsetenv("GRPC_CERT")
+		os.Unsetenv("GRPC_KEY")
+
+		// token-only clients can't present a certificate
+		cfg, err := mTLSConfig(certPEM, keyPEM, []byte(ca), env.Get("CACHER_AUTH_TOKENS") != "")
+		if err != nil {
+			return nil, nil, err
+		}
+
+		opts = append(opts, grpc.ServerOption(ggrpc.Creds(credentials.NewTLS(cfg))))
+		a.mtls = true
+
+		if f := env.Get("CACHER_AUTHZ_POLICY"); f != "" {
+			a.policy, err = loadAuthzPolicy(f)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	if f := env.Get("CACHER_AUTH_TOKENS"); f != "" {
+		var err error
+
+		a.tokens, err = newTokenStore(f)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		go a.tokens.watch(ctx, env.Duration("CACHER_AUTH_TOKENS_RELOAD_INTERVAL", 10*time.Second))
+	}
+
+	if !a.mtls && a.tokens == nil {
+		return nil, nil, nil
+	}
+
+	opts = append(opts,
+		grpc.UnaryInterceptor(a.unaryInterceptor),
+		grpc.StreamInterceptor(a.streamInterceptor),
+	)
+
+	return a, opts, nil
+}
+
 func versionHandler(w http.ResponseWriter, _ *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -86,6 +336,128 @@ func versionHandler(w http.ResponseWriter, _ *http.Request) {
 	}
 }
 
+// listHandlerPageSize is the page_size of listHandler when none is given.
+const listHandlerPageSize = 1000
+
+// listHandler serves All over HTTP as JSON lines, taking the AllRequest fields (except batch_size) as query parameters,
+// with the field mask as comma separated fields.
+// The token of the next page, if any, is returned in the X-Next-Page-Token header.
+// Stores that can't page have everything streamed instead.
+func (s *server) listHandler(w http.ResponseWriter, r *http.Request) {
+	labels := prometheus.Labels{"method": "All", "op": "http"}
+
+	cacheTotals.With(labels).Inc()
+	cacheInFlight.With(labels).Inc()
+	defer cacheInFlight.With(labels).Dec()
+
+	s.ingestReadyLock.RLock()
+	ready := s.ingestDone
+	s.ingestReadyLock.RUnlock()
+	if !ready {
+		cacheStalls.With(labels).Inc()
+		http.Error(w, "DB is not ready", http.StatusServiceUnavailable)
+
+		return
+	}
+
+	q := r.URL.Query()
+	in := &cacher.AllRequest{
+		PageToken:      q.Get("page_token"),
+		Filter:         q.Get("filter"),
+		FilterLanguage: q.Get("filter_language"),
+	}
+
+	if v := q.Get("fields"); v != "" {
+		in.FieldMask = &fieldmaskpb.FieldMask{Paths: strings.Split(v, ",")}
+	}
+
+	if v := q.Get("include_deleted"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			cacheErrors.With(labels).Inc()
+			http.Error(w, "invalid include_deleted", http.StatusBadRequest)
+
+			return
+		}
+
+		in.IncludeDeleted = b
+	}
+
+	if v := q.Get("page_size"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 32)
+		if err != nil {
+			cacheErrors.With(labels).Inc()
+			http.Error(w, "invalid page_size", http.StatusBadRequest)
+
+			return
+		}
+
+		in.PageSize = int32(n)
+	}
+
+	_, pages := s.store.(*hardware.Hardware)
+	if pages && in.PageSize == 0 {
+		in.PageSize = listHandlerPageSize
+	}
+
+	timer := prometheus.NewTimer(cacheDuration.With(labels))
+	defer timer.ObserveDuration()
+
+	// a page is buffered so errors can still be reported with a proper status, and the next page token in a header
+	buf := &bytes.Buffer{}
+	streamed := false
+
+	next, err := s.list(in, func(j string) error {
+		if !pages && !streamed {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			streamed = true
+		}
+
+		buf.WriteString(j)
+		buf.WriteByte('\n')
+
+		if pages {
+			return nil
+		}
+
+		_, err := buf.WriteTo(w)
+
+		return err
+	})
+	if err != nil {
+		cacheErrors.With(labels).Inc()
+
+		if streamed {
+			logger.Error(fmt.Errorf("listHandler: %w", err))
+			// the status is gone already, only breaking the connection tells the client the response is incomplete
+			panic(http.ErrAbortHandler)
+		}
+
+		code := http.StatusInternalServerError
+		switch status.Code(err) {
+		case codes.InvalidArgument:
+			code = http.StatusBadRequest
+		case codes.Unimplemented:
+			code = http.StatusNotImplemented
+		}
+
+		http.Error(w, status.Convert(err).Message(), code)
+
+		return
+	}
+
+	cacheHits.With(labels).Inc()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	if next != "" {
+		w.Header().Set("X-Next-Page-Token", next)
+	}
+
+	if _, err := buf.WriteTo(w); err != nil {
+		logger.Error(fmt.Errorf("listHandler write: %w", err))
+	}
+}
+
 func healthCheckHandler(w http.ResponseWriter, _ *http.Request) {
 	res := struct {
 		GitRev     string  `json:"git_rev"`
@@ -128,11 +500,37 @@ func setupGitRevJSON() {
 	gitRevJSON = b
 }
 
-func setupHTTP(ctx context.Context, certPEM []byte, modTime time.Time, errCh chan<- error) *http.Server {
+// metricsHandler returns the handler serving /metrics, left open to scrapers unless CACHER_METRICS_AUTH is set, in which
+// case it needs a token with the read scope.
+func metricsHandler(auth *authz) http.Handler {
+	if !env.Bool("CACHER_METRICS_AUTH") {
+		return promhttp.Handler()
+	}
+
+	return auth.requireScope(scopeRead, promhttp.Handler())
+}
+
+// hardwareHandler returns the handler serving /hardware to readers, subject to the limits of All, or nil if it must not
+// be served: without bearer tokens requireScope lets every request through, which mTLS alone must not allow.
+func (s *server) hardwareHandler(auth *authz, limits *limiter) http.Handler {
+	if auth != nil && auth.mtls && auth.tokens == nil {
+		return nil
+	}
+
+	return auth.requireScope(scopeRead, limits.limitHTTP("All", http.HandlerFunc(s.listHandler)))
+}
+
+func setupHTTP(ctx context.Context, s *server, auth *authz, limits *limiter, errCh chan<- error) *http.Server {
+	certPEM, modTime := s.Cert(), s.ModTime()
 	http.HandleFunc("/cert", func(w http.ResponseWriter, r *http.Request) {
 		http.ServeContent(w, r, "server.pem", modTime, bytes.NewReader(certPEM))
 	})
-	http.Handle("/metrics", promhttp.Handler())
+	http.Handle("/metrics", metricsHandler(auth))
+	if h := s.hardwareHandler(auth, limits); h != nil {
+		http.Handle("/hardware", h)
+	} else {
+		logger.Info("not serving /hardware, mTLS is enabled without CACHER_AUTH_TOKENS to authenticate http requests")
+	}
 	setupGitRevJSON()
 	http.HandleFunc("/version", versionHandler)
 	http.HandleFunc("/_packet/healthcheck", healthCheckHandler)
@@ -190,9 +588,30 @@ func main() {
 
 	ctx, closer := context.WithCancel(ctx)
 	errCh := make(chan error, 2)
-	srv := setupGRPC(ctx, client, errCh)
 
-	setupHTTP(ctx, srv.Cert(), srv.ModTime(), errCh)
+	auth, opts, err := setupAuth(ctx)
+	if err != nil {
+		logger.Fatal(errors.Wrap(err, "setup auth"))
+	}
+
+	// limits go after auth so clients can be told apart by their authenticated name
+	limits, err := newLimiterFromEnv()
+	if err != nil {
+		logger.Fatal(errors.Wrap(err, "setup limits"))
+	}
+
+	if limits != nil {
+		go limits.sweep(ctx, 10*time.Minute)
+
+		opts = append(opts,
+			grpc.UnaryInterceptor(limits.unaryInterceptor),
+			grpc.StreamInterceptor(limits.streamInterceptor),
+		)
+	}
+
+	srv := setupGRPC(ctx, client, opts, errCh)
+
+	setupHTTP(ctx, srv, auth, limits, errCh)
 
 	if err := srv.ingest(ctx, api, facility); err != nil {
 		logger.Error(err)
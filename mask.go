@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// fieldMask is a tree of the JSON object keys to keep, a nil subtree keeps the whole value.
+type fieldMask map[string]fieldMask
+
+// newFieldMask parses the dotted paths of m, returning nil if m has none.
+// Paths naming a parent and a child, like "instance" and "instance.id", keep the whole parent.
+func newFieldMask(m *fieldmaskpb.FieldMask) (fieldMask, error) {
+	if len(m.GetPaths()) == 0 {
+		return nil, nil
+	}
+
+	mask := fieldMask{}
+	for _, p := range m.GetPaths() {
+		keys := strings.Split(p, ".")
+
+		node := mask
+		for i, k := range keys {
+			if k == "" {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid field mask path %q", p)
+			}
+
+			child, ok := node[k]
+			switch {
+			case ok && child == nil:
+				// a parent is already kept whole
+			case i == len(keys)-1:
+				node[k] = nil
+			case !ok:
+				child = fieldMask{}
+				node[k] = child
+			}
+
+			if child == nil {
+				break
+			}
+
+			node = child
+		}
+	}
+
+	return mask, nil
+}
+
+// apply trims the JSON document j to the paths in m, paths through arrays apply to each element.
+// Paths that don't exist in j are ignored.
+func (m fieldMask) apply(j string) (string, error) {
+	if m == nil || j == "" {
+		return j, nil
+	}
+
+	// numbers are kept as they are written, rather than as float64s losing the precision of large ints
+	d := json.NewDecoder(strings.NewReader(j))
+	d.UseNumber()
+
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return "", errors.Wrap(err, "decode json")
+	}
+
+	// and so are <, > and &, which Marshal escapes
+	b := &strings.Builder{}
+	e := json.NewEncoder(b)
+	e.SetEscapeHTML(false)
+
+	if err := e.Encode(m.trim(v)); err != nil {
+		return "", errors.Wrap(err, "encode json")
+	}
+
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+func (m fieldMask) trim(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		trimmed := make(map[string]interface{}, len(m))
+		for k, sub := range m {
+			cv, ok := v[k]
+			if !ok {
+				continue
+			}
+
+			if sub != nil {
+				cv = sub.trim(cv)
+			}
+
+			trimmed[k] = cv
+		}
+
+		return trimmed
+	case []interface{}:
+		for i := range v {
+			v[i] = m.trim(v[i])
+		}
+
+		return v
+	}
+
+	// scalars have no fields to select
+	return v
+}
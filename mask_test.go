@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+func TestFieldMask(t *testing.T) {
+	assert := require.New(t)
+
+	hw := `{"id":"1","state":"active","instance":{"id":"2","ip_addresses":[{"address":"10.0.0.1","public":false}]},"network_ports":[{"name":"eth0","data":{"mac":"00:00:00:00:00:01","bond":"bond0"}},{"name":"eth1","data":{"mac":"00:00:00:00:00:02"}}]}`
+
+	for _, test := range []struct {
+		paths []string
+		want  string
+	}{
+		{paths: nil, want: hw},
+		{paths: []string{"id"}, want: `{"id":"1"}`},
+		{paths: []string{"id", "network_ports.data.mac"}, want: `{"id":"1","network_ports":[{"data":{"mac":"00:00:00:00:00:01"}},{"data":{"mac":"00:00:00:00:00:02"}}]}`},
+		{paths: []string{"instance.ip_addresses.address"}, want: `{"instance":{"ip_addresses":[{"address":"10.0.0.1"}]}}`},
+		{paths: []string{"instance.id", "instance"}, want: `{"instance":{"id":"2","ip_addresses":[{"address":"10.0.0.1","public":false}]}}`},
+		{paths: []string{"instance", "instance.id"}, want: `{"instance":{"id":"2","ip_addresses":[{"address":"10.0.0.1","public":false}]}}`},
+		{paths: []string{"plan.slug", "state.name"}, want: `{"state":"active"}`},
+	} {
+		mask, err := newFieldMask(&fieldmaskpb.FieldMask{Paths: test.paths})
+		assert.NoError(err)
+
+		got, err := mask.apply(hw)
+		assert.NoError(err)
+		assert.JSONEq(test.want, got, "%v", test.paths)
+	}
+
+	// large numbers and html characters are kept as they are
+	mask, err := newFieldMask(&fieldmaskpb.FieldMask{Paths: []string{"plan", "facility"}})
+	assert.NoError(err)
+	got, err := mask.apply(`{"id":"1","plan":{"memory":9007199254740993,"ratio":0.1},"facility":"<ewr1> & <sjc1>"}`)
+	assert.NoError(err)
+	assert.Equal(`{"facility":"<ewr1> & <sjc1>","plan":{"memory":9007199254740993,"ratio":0.1}}`, got)
+
+	_, err = newFieldMask(&fieldmaskpb.FieldMask{Paths: []string{"network_ports..mac"}})
+	assert.Equal(codes.InvalidArgument, status.Code(err))
+
+	// no document stays no document
+	mask, err = newFieldMask(&fieldmaskpb.FieldMask{Paths: []string{"id"}})
+	assert.NoError(err)
+	got, err = mask.apply("")
+	assert.NoError(err)
+	assert.Empty(got)
+}
@@ -1,11 +1,17 @@
 package main
 
 import (
+	"github.com/packethost/cacher/hardware"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
 var (
+	authDenied   *prometheus.CounterVec
+	authRequests *prometheus.CounterVec
+
+	auditErrors prometheus.Counter
+
 	cacheCountTotal prometheus.Gauge
 	cacheDuration   prometheus.ObserverVec
 	cacheErrors     *prometheus.CounterVec
@@ -16,14 +22,46 @@ var (
 
 	cacherState prometheus.Gauge
 
+	compressionSeconds *prometheus.CounterVec
+
+	conflicts *prometheus.GaugeVec
+
 	ingestCount    *prometheus.CounterVec
 	ingestDuration *prometheus.GaugeVec
 	ingestErrors   *prometheus.CounterVec
 
+	pushUnchanged *prometheus.CounterVec
+
+	fsckProblems *prometheus.GaugeVec
+	fsckRepairs  prometheus.Counter
+
+	illegalTransitions *prometheus.CounterVec
+
+	rateLimited *prometheus.CounterVec
+
+	schemaViolations *prometheus.CounterVec
+
+	tombstones      prometheus.Gauge
+	tombstonesSwept prometheus.Counter
+
 	watchMissTotal prometheus.Counter
 )
 
 func setupMetrics() {
+	authDenied = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_denied_total",
+		Help: "Number of requests denied by the authorization policy.",
+	}, []string{"method"})
+	authRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_requests_total",
+		Help: "Number of authorized requests by caller.",
+	}, []string{"method", "caller"})
+
+	auditErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "audit_errors_total",
+		Help: "Number of mutations that could not be recorded in the audit log.",
+	})
+
 	cacheCountTotal = promauto.NewGauge(prometheus.GaugeOpts{
 		Name: "cache_count_total",
 		Help: "Number of in devices in memory.",
@@ -60,7 +98,11 @@ func setupMetrics() {
 
 	labels = []prometheus.Labels{
 		{"method": "Push", "op": ""},
+		{"method": "Patch", "op": ""},
+		{"method": "PushBatch", "op": ""},
+		{"method": "PushStream", "op": ""},
 		{"method": "Ingest", "op": ""},
+		{"method": "Fsck", "op": ""},
 	}
 	initCounterLabels(cacheErrors, labels)
 	initGaugeLabels(cacheInFlight, labels)
@@ -69,6 +111,7 @@ func setupMetrics() {
 	labels = []prometheus.Labels{
 		{"method": "Push", "op": "insert"},
 		{"method": "Push", "op": "delete"},
+		{"method": "Fsck", "op": ""},
 	}
 	initObserverLabels(cacheDuration, labels)
 	initCounterLabels(cacheHits, labels)
@@ -77,8 +120,16 @@ func setupMetrics() {
 		{"method": "ByMAC", "op": "get"},
 		{"method": "ByIP", "op": "get"},
 		{"method": "ByID", "op": "get"},
+		{"method": "ByCIDR", "op": "get"},
+		{"method": "ByIndex", "op": "get"},
+		{"method": "ByPort", "op": "get"},
+		{"method": "Conflicts", "op": "get"},
+		{"method": "History", "op": "get"},
 		{"method": "All", "op": "get"},
+		{"method": "All", "op": "http"},
+		{"method": "Audit", "op": "get"},
 		{"method": "Ingest", "op": ""},
+		{"method": "StateGraph", "op": "get"},
 		{"method": "Watch", "op": "get"},
 		{"method": "Watch", "op": "push"},
 	}
@@ -89,11 +140,42 @@ func setupMetrics() {
 	initObserverLabels(cacheDuration, labels)
 	initCounterLabels(cacheHits, labels)
 
+	labels = []prometheus.Labels{
+		{"method": "Push"},
+		{"method": "Patch"},
+		{"method": "PushBatch"},
+		{"method": "PushStream"},
+		{"method": "ByMAC"},
+		{"method": "ByIP"},
+		{"method": "ByID"},
+		{"method": "ByCIDR"},
+		{"method": "ByIndex"},
+		{"method": "ByPort"},
+		{"method": "Conflicts"},
+		{"method": "History"},
+		{"method": "All"},
+		{"method": "Audit"},
+		{"method": "Ingest"},
+		{"method": "Fsck"},
+		{"method": "StateGraph"},
+		{"method": "Watch"},
+	}
+	initCounterLabels(authDenied, labels)
+
 	cacherState = promauto.NewGauge(prometheus.GaugeOpts{
 		Name: "cacher_state",
 		Help: "Reports cacher state, 0:started, 1:ingesting, 2:ready",
 	})
 
+	conflicts = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "cache_conflicts",
+		Help: "Number of mac and ip addresses claimed by more than one hardware.",
+	}, []string{"kind"})
+	initGaugeLabels(conflicts, []prometheus.Labels{
+		{"kind": hardware.ConflictMAC},
+		{"kind": hardware.ConflictIP},
+	})
+
 	ingestCount = promauto.NewCounterVec(prometheus.CounterOpts{
 		Name: "ingest_op_count_total",
 		Help: "Number of attempts made to ingest facility data.",
@@ -115,10 +197,102 @@ func setupMetrics() {
 	initGaugeLabels(ingestDuration, labels)
 	initCounterLabels(ingestErrors, labels)
 
+	pushUnchanged = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cache_push_unchanged_total",
+		Help: "Number of pushed documents identical to the stored ones, which were not stored again.",
+	}, []string{"method"})
+	initCounterLabels(pushUnchanged, []prometheus.Labels{
+		{"method": "Push"},
+		{"method": "Patch"},
+		{"method": "PushBatch"},
+		{"method": "PushStream"},
+	})
+
+	rateLimited = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rate_limited_total",
+		Help: "Number of requests rejected for exceeding a per-client rate or concurrency limit.",
+	}, []string{"method", "reason"})
+	labels = []prometheus.Labels{}
+	for method, class := range limitClasses {
+		if class == unlimited {
+			continue
+		}
+
+		labels = append(labels,
+			prometheus.Labels{"method": method, "reason": "rate"},
+			prometheus.Labels{"method": method, "reason": "concurrency"},
+		)
+	}
+	initCounterLabels(rateLimited, labels)
+
+	fsckProblems = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "cache_fsck_problems",
+		Help: "Number of index entries disagreeing with the stored hardware found by the last check, by index and kind.",
+	}, []string{"index", "kind"})
+	labels = []prometheus.Labels{}
+	for _, index := range fsckIndexes {
+		for _, kind := range fsckKinds {
+			labels = append(labels, prometheus.Labels{"index": index, "kind": kind})
+		}
+	}
+	initGaugeLabels(fsckProblems, labels)
+	fsckRepairs = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "cache_fsck_repairs_total",
+		Help: "Number of index entries repaired.",
+	})
+
+	illegalTransitions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cache_illegal_transitions_total",
+		Help: "Number of pushes moving hardware to a state it isn't allowed to go to, by state and policy.",
+	}, []string{"from", "to", "policy"})
+
+	schemaViolations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cache_schema_violations_total",
+		Help: "Number of documents violating the hardware schema, by mode.",
+	}, []string{"mode"})
+	initCounterLabels(schemaViolations, []prometheus.Labels{{"mode": "warn"}, {"mode": "strict"}})
+
+	tombstones = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "cache_tombstones",
+		Help: "Number of deleted devices retained as tombstones.",
+	})
+	tombstonesSwept = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "cache_tombstones_swept_total",
+		Help: "Number of expired tombstones removed.",
+	})
+
 	watchMissTotal = promauto.NewCounter(prometheus.CounterOpts{
 		Name: "watch_miss_count_total",
 		Help: "Number of missed updates due to a blocked channel.",
 	})
+
+	compressionSeconds = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cache_compression_seconds_total",
+		Help: "Time spent compressing and decompressing documents.",
+	}, []string{"op"})
+	initCounterLabels(compressionSeconds, []prometheus.Labels{{"op": "compress"}, {"op": "decompress"}})
+}
+
+// setupDocumentMetrics reports the size of the documents kept by hw, as they are and as stored.
+func setupDocumentMetrics(hw *hardware.Hardware) {
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name:        "cache_document_bytes",
+		Help:        "Size of the documents kept, including tombstones and revisions.",
+		ConstLabels: prometheus.Labels{"form": "raw"},
+	}, func() float64 {
+		raw, _ := hw.DocumentBytes()
+
+		return float64(raw)
+	})
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name:        "cache_document_bytes",
+		Help:        "Size of the documents kept, including tombstones and revisions.",
+		ConstLabels: prometheus.Labels{"form": "stored"},
+	}, func() float64 {
+		_, stored := hw.DocumentBytes()
+
+		return float64(stored)
+	})
 }
 
 func initObserverLabels(m prometheus.ObserverVec, l []prometheus.Labels) {
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/packethost/pkg/env"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// unlimited is the class of the methods that are not limited.
+const unlimited = ""
+
+// limitClasses groups the Cacher methods that share a limit, every method is listed, see TestMethodTables.
+var limitClasses = map[string]string{
+	"ByMAC":      "lookup",
+	"ByIP":       "lookup",
+	"ByID":       "lookup",
+	"ByCIDR":     "lookup",
+	"ByIndex":    "lookup",
+	"ByPort":     "lookup",
+	"Conflicts":  "lookup",
+	"History":    "lookup",
+	"All":        "all",
+	"Watch":      "watch",
+	"Push":       "push",
+	"Patch":      "push",
+	"PushBatch":  "push",
+	"PushStream": "push",
+	"StateGraph": "lookup",
+	"Audit":      unlimited,
+	"Fsck":       unlimited,
+	"Ingest":     unlimited,
+}
+
+// concurrencyRetryDelay is the retry delay suggested to clients that hit a max in-flight limit.
+const concurrencyRetryDelay = time.Second
+
+// limit is the configuration of a single limit class, zero values mean unlimited.
+type limit struct {
+	rate     rate.Limit
+	burst    int
+	inFlight int
+}
+
+func (l limit) enabled() bool {
+	return l.rate > 0 || l.inFlight > 0
+}
+
+// limitFromEnv reads the limit of class from CACHER_LIMIT_<CLASS>_RATE (requests/second),
+// CACHER_LIMIT_<CLASS>_BURST and CACHER_LIMIT_<CLASS>_IN_FLIGHT.
+func limitFromEnv(class string) (limit, error) {
+	prefix := "CACHER_LIMIT_" + strings.ToUpper(class) + "_"
+
+	l := limit{
+		burst:    env.Int(prefix+"BURST", 0),
+		inFlight: env.Int(prefix+"IN_FLIGHT", 0),
+	}
+
+	if v := env.Get(prefix + "RATE"); v != "" {
+		r, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return l, errors.Wrapf(err, "parse %sRATE", prefix)
+		}
+
+		l.rate = rate.Limit(r)
+	}
+
+	if l.rate > 0 && l.burst < 1 {
+		l.burst = int(l.rate)
+		if l.burst < 1 {
+			l.burst = 1
+		}
+	}
+
+	return l, nil
+}
+
+// clientLimiter tracks the limits of a single client within a class.
+type clientLimiter struct {
+	bucket   *rate.Limiter
+	inFlight int
+	lastSeen time.Time
+}
+
+// classLimiter applies a limit separately to every client.
+type classLimiter struct {
+	limit limit
+
+	mu      sync.Mutex
+	clients map[string]*clientLimiter
+}
+
+// acquire takes a token and an in-flight slot for client.
+// On success release must be called once the request is done, otherwise the reason and suggested retry delay are returned.
+func (c *classLimiter) acquire(client string, now time.Time) (release func(), reason string, retry time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cl, ok := c.clients[client]
+	if !ok {
+		cl = &clientLimiter{}
+		if c.limit.rate > 0 {
+			cl.bucket = rate.NewLimiter(c.limit.rate, c.limit.burst)
+		}
+
+		c.clients[client] = cl
+	}
+
+	cl.lastSeen = now
+
+	if c.limit.inFlight > 0 && cl.inFlight >= c.limit.inFlight {
+		return nil, "concurrency", concurrencyRetryDelay
+	}
+
+	if cl.bucket != nil {
+		r := cl.bucket.ReserveN(now, 1)
+		if d := r.DelayFrom(now); d > 0 {
+			r.CancelAt(now)
+
+			return nil, "rate", d
+		}
+	}
+
+	cl.inFlight++
+
+	return func() {
+		c.mu.Lock()
+		cl.inFlight--
+		c.mu.Unlock()
+	}, "", 0
+}
+
+// sweep forgets clients that have been idle since before cutoff, their buckets would be full again anyway.
+func (c *classLimiter) sweep(cutoff time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k, cl := range c.clients {
+		if cl.inFlight == 0 && cl.lastSeen.Before(cutoff) {
+			delete(c.clients, k)
+		}
+	}
+}
+
+// limiter enforces per-client rate and in-flight limits on Cacher methods.
+// Clients are identified by their authenticated name, or their peer IP if unauthenticated.
+type limiter struct {
+	classes map[string]*classLimiter
+}
+
+// newLimiterFromEnv returns a limiter configured from the environment, or nil if no limits are set.
+func newLimiterFromEnv() (*limiter, error) {
+	l := &limiter{classes: map[string]*classLimiter{}}
+
+	for _, class := range limitClasses {
+		if _, ok := l.classes[class]; ok || class == unlimited {
+			continue
+		}
+
+		lim, err := limitFromEnv(class)
+		if err != nil {
+			return nil, err
+		}
+
+		if !lim.enabled() {
+			continue
+		}
+
+		l.classes[class] = &classLimiter{limit: lim, clients: map[string]*clientLimiter{}}
+	}
+
+	if len(l.classes) == 0 {
+		return nil, nil
+	}
+
+	return l, nil
+}
+
+// clientKey identifies the caller in ctx for limiting purposes.
+func clientKey(ctx context.Context) string {
+	if name := callerName(ctx); name != "" {
+		return name
+	}
+
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	return hostOf(p.Addr.String())
+}
+
+// hostOf returns the host of addr, or addr if it has no port.
+func hostOf(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+
+	return host
+}
+
+// take acquires a slot of the class of method for the client with key.
+// The release func is nil if a limit is exceeded, reason and retry then tell which one and when to try again.
+func (l *limiter) take(method, key string) (release func(), reason string, retry time.Duration) {
+	c, ok := l.classes[limitClasses[method]]
+	if !ok {
+		return func() {}, "", 0
+	}
+
+	release, reason, retry = c.acquire(key, time.Now())
+	if release == nil {
+		rateLimited.With(prometheus.Labels{"method": method, "reason": reason}).Inc()
+	}
+
+	return release, reason, retry
+}
+
+func (l *limiter) acquire(ctx context.Context, fullMethod string) (func(), error) {
+	if !strings.HasPrefix(fullMethod, cacherServicePrefix) {
+		return func() {}, nil
+	}
+
+	method := path.Base(fullMethod)
+
+	release, reason, retry := l.take(method, clientKey(ctx))
+	if release != nil {
+		return release, nil
+	}
+
+	st, err := status.New(codes.ResourceExhausted, method+": "+reason+" limit exceeded").
+		WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(retry)})
+	if err != nil {
+		return nil, status.Error(codes.ResourceExhausted, method+": "+reason+" limit exceeded")
+	}
+
+	return nil, st.Err()
+}
+
+// limitHTTP wraps an HTTP handler serving the same as method so it shares its limits, requests over a limit fail with
+// 429 and a Retry-After header.
+// Clients are told apart by the name requireScope authenticated them as, or else their remote IP, so it must be wrapped
+// by requireScope rather than the other way around. It is a no-op on a nil limiter.
+func (l *limiter) limitHTTP(method string, h http.Handler) http.Handler {
+	if l == nil {
+		return h
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := callerName(r.Context())
+		if key == "" {
+			key = hostOf(r.RemoteAddr)
+		}
+
+		release, reason, retry := l.take(method, key)
+		if release == nil {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retry.Seconds()))))
+			http.Error(w, method+": "+reason+" limit exceeded", http.StatusTooManyRequests)
+
+			return
+		}
+		defer release()
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+// sweep periodically forgets idle clients until ctx is done.
+func (l *limiter) sweep(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-t.C:
+			for _, c := range l.classes {
+				c.sweep(now.Add(-interval))
+			}
+		}
+	}
+}
+
+func (l *limiter) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	release, err := l.acquire(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return handler(ctx, req)
+}
+
+func (l *limiter) streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	release, err := l.acquire(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return handler(srv, ss)
+}
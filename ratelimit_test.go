@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestLimitFromEnv(t *testing.T) {
+	assert := require.New(t)
+
+	l, err := newLimiterFromEnv()
+	assert.NoError(err)
+	assert.Nil(l)
+
+	os.Setenv("CACHER_LIMIT_ALL_RATE", "0.5")
+	os.Setenv("CACHER_LIMIT_PUSH_IN_FLIGHT", "2")
+	defer os.Unsetenv("CACHER_LIMIT_ALL_RATE")
+	defer os.Unsetenv("CACHER_LIMIT_PUSH_IN_FLIGHT")
+
+	l, err = newLimiterFromEnv()
+	assert.NoError(err)
+	assert.Len(l.classes, 2)
+	assert.Equal(limit{rate: rate.Limit(0.5), burst: 1}, l.classes["all"].limit)
+	assert.Equal(limit{inFlight: 2}, l.classes["push"].limit)
+
+	os.Setenv("CACHER_LIMIT_ALL_RATE", "fast")
+	_, err = newLimiterFromEnv()
+	assert.Error(err)
+}
+
+func TestLimiter(t *testing.T) {
+	assert := require.New(t)
+
+	l := &limiter{classes: map[string]*classLimiter{
+		"lookup": {limit: limit{rate: 1, burst: 2}, clients: map[string]*clientLimiter{}},
+		"push":   {limit: limit{inFlight: 1}, clients: map[string]*clientLimiter{}},
+	}}
+
+	peerA := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 1}})
+	peerB := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 2), Port: 1}})
+	// a different port on the same host is the same client
+	peerA2 := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 2}})
+
+	limited := testutil.ToFloat64(rateLimited.With(prometheus.Labels{"method": "ByIP", "reason": "rate"}))
+
+	for i := 0; i < 2; i++ {
+		release, err := l.acquire(peerA, "/cacher.Cacher/ByMAC")
+		assert.NoError(err)
+		release()
+	}
+
+	_, err := l.acquire(peerA2, "/cacher.Cacher/ByIP")
+	assert.Equal(codes.ResourceExhausted, status.Code(err))
+	assert.Equal(limited+1, testutil.ToFloat64(rateLimited.With(prometheus.Labels{"method": "ByIP", "reason": "rate"})))
+
+	details := status.Convert(err).Details()
+	assert.Len(details, 1)
+	retry, ok := details[0].(*errdetails.RetryInfo)
+	assert.True(ok)
+	assert.Greater(retry.RetryDelay.AsDuration(), time.Duration(0))
+
+	// other clients and unlimited methods are unaffected
+	release, err := l.acquire(peerB, "/cacher.Cacher/ByMAC")
+	assert.NoError(err)
+	release()
+	release, err = l.acquire(peerA, "/cacher.Cacher/All")
+	assert.NoError(err)
+	release()
+
+	// in flight limits are per authenticated caller
+	apiSync := withCaller(peerA, "sync")
+	release, err = l.acquire(apiSync, "/cacher.Cacher/Push")
+	assert.NoError(err)
+	_, err = l.acquire(withCaller(peerB, "sync"), "/cacher.Cacher/Push")
+	assert.Equal(codes.ResourceExhausted, status.Code(err))
+	r, err := l.acquire(withCaller(peerA, "tink"), "/cacher.Cacher/Push")
+	assert.NoError(err)
+	r()
+	release()
+	release, err = l.acquire(apiSync, "/cacher.Cacher/Push")
+	assert.NoError(err)
+	release()
+
+	// idle clients are forgotten
+	l.classes["lookup"].sweep(time.Now().Add(time.Minute))
+	assert.Empty(l.classes["lookup"].clients)
+}
+
+func TestLimitHTTP(t *testing.T) {
+	assert := require.New(t)
+
+	l := &limiter{classes: map[string]*classLimiter{
+		"all": {limit: limit{rate: 1, burst: 1}, clients: map[string]*clientLimiter{}},
+	}}
+
+	h := l.limitHTTP("All", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+
+	get := func(remote, caller string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/hardware", nil)
+		r.RemoteAddr = remote
+		if caller != "" {
+			r = r.WithContext(withCaller(r.Context(), caller))
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		return w
+	}
+
+	limited := testutil.ToFloat64(rateLimited.With(prometheus.Labels{"method": "All", "reason": "rate"}))
+
+	assert.Equal(http.StatusOK, get("10.0.0.1:1", "").Code)
+
+	w := get("10.0.0.1:2", "")
+	assert.Equal(http.StatusTooManyRequests, w.Code)
+	assert.Equal("1", w.Header().Get("Retry-After"))
+	assert.Equal(limited+1, testutil.ToFloat64(rateLimited.With(prometheus.Labels{"method": "All", "reason": "rate"})))
+
+	// authenticated callers are limited by name
+	assert.Equal(http.StatusOK, get("10.0.0.1:3", "tink").Code)
+	assert.Equal(http.StatusTooManyRequests, get("10.0.0.2:1", "tink").Code)
+	assert.Equal(http.StatusOK, get("10.0.0.2:2", "").Code)
+
+	var nl *limiter
+	assert.NotNil(nl.limitHTTP("All", h))
+}
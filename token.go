@@ -0,0 +1,248 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	scopeRead  = "read"
+	scopeWatch = "watch"
+	scopePush  = "push"
+	scopeAdmin = "admin"
+)
+
+// methodScopes is the scope a token needs to call each Cacher method, every method is listed, see TestMethodTables.
+// Methods missing anyway need admin.
+var methodScopes = map[string]string{
+	"ByMAC":      scopeRead,
+	"ByIP":       scopeRead,
+	"ByID":       scopeRead,
+	"ByCIDR":     scopeRead,
+	"ByIndex":    scopeRead,
+	"ByPort":     scopeRead,
+	"Conflicts":  scopeRead,
+	"History":    scopeRead,
+	"All":        scopeRead,
+	"Watch":      scopeWatch,
+	"Push":       scopePush,
+	"Patch":      scopePush,
+	"PushBatch":  scopePush,
+	"PushStream": scopePush,
+	"StateGraph": scopeRead,
+	"Audit":      scopeAdmin,
+	"Fsck":       scopeAdmin,
+	"Ingest":     scopeAdmin,
+}
+
+// token is a static bearer token as read from the tokens file.
+type token struct {
+	Name   string   `json:"name"`
+	Token  string   `json:"token"`
+	Scopes []string `json:"scopes"`
+}
+
+func (t token) hasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scopeAdmin || s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allows reports whether t carries the scope needed to call method.
+func (t token) allows(method string) bool {
+	scope, ok := methodScopes[method]
+	if !ok {
+		scope = scopeAdmin
+	}
+
+	return t.hasScope(scope)
+}
+
+// tokenStore holds the tokens read from a file, keyed by their sha256 so lookups don't compare secrets directly.
+type tokenStore struct {
+	file string
+
+	mu     sync.RWMutex
+	tokens map[[sha256.Size]byte]token
+	modT   time.Time
+	size   int64
+}
+
+func newTokenStore(file string) (*tokenStore, error) {
+	s := &tokenStore{file: file}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *tokenStore) load() error {
+	fi, err := os.Stat(s.file)
+	if err != nil {
+		return errors.Wrap(err, "stat tokens file")
+	}
+
+	b, err := os.ReadFile(s.file)
+	if err != nil {
+		return errors.Wrap(err, "read tokens file")
+	}
+
+	f := struct {
+		Tokens []token `json:"tokens"`
+	}{}
+	if err := json.Unmarshal(b, &f); err != nil {
+		return errors.Wrap(err, "decode tokens file")
+	}
+
+	tokens := make(map[[sha256.Size]byte]token, len(f.Tokens))
+	for i, t := range f.Tokens {
+		if t.Name == "" || t.Token == "" {
+			return errors.Errorf("token %d needs a name and a token", i)
+		}
+
+		for _, scope := range t.Scopes {
+			switch scope {
+			case scopeRead, scopeWatch, scopePush, scopeAdmin:
+			default:
+				return errors.Errorf("token %q has unknown scope %q", t.Name, scope)
+			}
+		}
+
+		sum := sha256.Sum256([]byte(t.Token))
+		if _, ok := tokens[sum]; ok {
+			return errors.Errorf("token %q is a duplicate", t.Name)
+		}
+
+		tokens[sum] = t
+	}
+
+	s.mu.Lock()
+	s.tokens = tokens
+	s.modT = fi.ModTime()
+	s.size = fi.Size()
+	s.mu.Unlock()
+
+	return nil
+}
+
+// changed reports whether the tokens file looks different from when it was last loaded.
+func (s *tokenStore) changed() bool {
+	fi, err := os.Stat(s.file)
+	if err != nil {
+		return false
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return !fi.ModTime().Equal(s.modT) || fi.Size() != s.size
+}
+
+// watch reloads the tokens file whenever it changes, checking every interval until ctx is done.
+// A file that fails to load is logged and the previous tokens are kept.
+func (s *tokenStore) watch(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if !s.changed() {
+				continue
+			}
+
+			if err := s.load(); err != nil {
+				logger.Error(errors.Wrap(err, "reload tokens"))
+				continue
+			}
+
+			logger.Info("reloaded tokens")
+		}
+	}
+}
+
+func (s *tokenStore) lookup(tok string) (token, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	t, ok := s.tokens[sha256.Sum256([]byte(tok))]
+
+	return t, ok
+}
+
+func parseBearer(v string) string {
+	const prefix = "bearer "
+	if len(v) < len(prefix) || !strings.EqualFold(v[:len(prefix)], prefix) {
+		return ""
+	}
+
+	return strings.TrimSpace(v[len(prefix):])
+}
+
+// bearerToken returns the token sent in the authorization metadata of a gRPC call.
+func bearerToken(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	for _, v := range md.Get("authorization") {
+		if tok := parseBearer(v); tok != "" {
+			return tok
+		}
+	}
+
+	return ""
+}
+
+// requireScope wraps an HTTP handler so it is only served to requests carrying a token with scope.
+// It is a no-op when token auth is not enabled.
+func (a *authz) requireScope(scope string, h http.Handler) http.Handler {
+	if a == nil || a.tokens == nil {
+		return h
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method := r.URL.Path
+
+		t, ok := a.tokens.lookup(parseBearer(r.Header.Get("Authorization")))
+		if !ok {
+			_ = a.deny(r.Context(), method, "", "invalid or missing bearer token")
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+
+			return
+		}
+
+		if !t.hasScope(scope) {
+			_ = a.deny(r.Context(), method, t.Name, "token lacks the required scope")
+			http.Error(w, "forbidden", http.StatusForbidden)
+
+			return
+		}
+
+		authRequests.With(prometheus.Labels{"method": method, "caller": t.Name}).Inc()
+		trace.SpanFromContext(r.Context()).SetAttributes(attribute.String("caller", t.Name))
+
+		h.ServeHTTP(w, r.WithContext(withCaller(r.Context(), t.Name)))
+	})
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func writeTokens(t *testing.T, file, tokens string) {
+	t.Helper()
+
+	require.NoError(t, os.WriteFile(file, []byte(tokens), 0o600))
+}
+
+func TestTokenStore(t *testing.T) {
+	assert := require.New(t)
+	f := filepath.Join(t.TempDir(), "tokens.json")
+
+	for _, tokens := range []string{
+		`{"tokens":[{"name":"boots","scopes":["read"]}]}`,
+		`{"tokens":[{"token":"t1","scopes":["read"]}]}`,
+		`{"tokens":[{"name":"boots","token":"t1","scopes":["write"]}]}`,
+		`{"tokens":[{"name":"boots","token":"t1"},{"name":"tink","token":"t1"}]}`,
+		`{"tokens":`,
+	} {
+		writeTokens(t, f, tokens)
+		_, err := newTokenStore(f)
+		assert.Error(err, tokens)
+	}
+
+	writeTokens(t, f, `{"tokens":[{"name":"boots","token":"t1","scopes":["read","watch"]}]}`)
+	s, err := newTokenStore(f)
+	assert.NoError(err)
+
+	tok, ok := s.lookup("t1")
+	assert.True(ok)
+	assert.Equal("boots", tok.Name)
+	assert.True(tok.allows("ByMAC"))
+	assert.True(tok.allows("Watch"))
+	assert.False(tok.allows("Push"))
+	assert.False(tok.allows("Ingest"))
+
+	_, ok = s.lookup("")
+	assert.False(ok)
+	_, ok = s.lookup("t2")
+	assert.False(ok)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go s.watch(ctx, 10*time.Millisecond)
+
+	// a broken file keeps the old tokens around
+	writeTokens(t, f, `{"tokens":`)
+	assert.NoError(os.Chtimes(f, time.Now(), time.Now().Add(time.Second)))
+	time.Sleep(50 * time.Millisecond)
+	_, ok = s.lookup("t1")
+	assert.True(ok)
+
+	writeTokens(t, f, `{"tokens":[{"name":"sync","token":"t2","scopes":["push"]}]}`)
+	assert.NoError(os.Chtimes(f, time.Now(), time.Now().Add(2*time.Second)))
+	assert.Eventually(func() bool {
+		_, ok := s.lookup("t2")
+
+		return ok
+	}, time.Second, 10*time.Millisecond)
+	_, ok = s.lookup("t1")
+	assert.False(ok)
+}
+
+func TestAuthorizeToken(t *testing.T) {
+	assert := require.New(t)
+	f := filepath.Join(t.TempDir(), "tokens.json")
+	writeTokens(t, f, `{"tokens":[{"name":"boots","token":"t1","scopes":["read"]},{"name":"ops","token":"t2","scopes":["admin"]}]}`)
+
+	s, err := newTokenStore(f)
+	assert.NoError(err)
+
+	a := &authz{tokens: s}
+
+	for _, test := range []struct {
+		auth   string
+		method string
+		caller string
+	}{
+		{auth: "Bearer t1", method: "/cacher.Cacher/ByMAC", caller: "boots"},
+		{auth: "bearer t1", method: "/cacher.Cacher/All", caller: "boots"},
+		{auth: "Bearer t1", method: "/cacher.Cacher/Push"},
+		{auth: "Bearer t2", method: "/cacher.Cacher/Push", caller: "ops"},
+		{auth: "Bearer t2", method: "/cacher.Cacher/Ingest", caller: "ops"},
+		{auth: "Bearer t3", method: "/cacher.Cacher/ByMAC"},
+		{auth: "Basic dDE=", method: "/cacher.Cacher/ByMAC"},
+		{auth: "", method: "/cacher.Cacher/ByMAC"},
+	} {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", test.auth))
+		ctx, err := a.authorize(ctx, test.method)
+		if test.caller != "" {
+			assert.NoError(err, test.auth)
+			assert.Equal(test.caller, callerName(ctx))
+		} else {
+			assert.Equal(codes.PermissionDenied, status.Code(err), test.auth)
+		}
+	}
+}
+
+func TestRequireScope(t *testing.T) {
+	assert := require.New(t)
+	f := filepath.Join(t.TempDir(), "tokens.json")
+	writeTokens(t, f, `{"tokens":[{"name":"boots","token":"t1","scopes":["read"]},{"name":"sync","token":"t2","scopes":["push"]}]}`)
+
+	s, err := newTokenStore(f)
+	assert.NoError(err)
+
+	var caller string
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		caller = callerName(r.Context())
+	})
+
+	for _, test := range []struct {
+		auth string
+		code int
+	}{
+		{auth: "Bearer t1", code: http.StatusOK},
+		{auth: "Bearer t2", code: http.StatusForbidden},
+		{auth: "Bearer t3", code: http.StatusUnauthorized},
+		{auth: "", code: http.StatusUnauthorized},
+	} {
+		caller = ""
+		r := httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody)
+		r.Header.Set("Authorization", test.auth)
+		w := httptest.NewRecorder()
+
+		(&authz{tokens: s}).requireScope(scopeRead, h).ServeHTTP(w, r)
+		assert.Equal(test.code, w.Code, test.auth)
+
+		if test.code == http.StatusOK {
+			assert.Equal("boots", caller)
+		}
+	}
+
+	// without token auth the handler is served as is
+	var a *authz
+	w := httptest.NewRecorder()
+	a.requireScope(scopeRead, h).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody))
+	assert.Equal(http.StatusOK, w.Code)
+}
+
+func TestMetricsHandler(t *testing.T) {
+	assert := require.New(t)
+	f := filepath.Join(t.TempDir(), "tokens.json")
+	writeTokens(t, f, `{"tokens":[{"name":"prometheus","token":"t1","scopes":["read"]}]}`)
+
+	s, err := newTokenStore(f)
+	assert.NoError(err)
+
+	a := &authz{tokens: s}
+	get := func(auth string) int {
+		r := httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody)
+		r.Header.Set("Authorization", auth)
+		w := httptest.NewRecorder()
+		metricsHandler(a).ServeHTTP(w, r)
+
+		return w.Code
+	}
+
+	// scrapers don't need a token by default
+	assert.Equal(http.StatusOK, get(""))
+
+	t.Setenv("CACHER_METRICS_AUTH", "true")
+	assert.Equal(http.StatusUnauthorized, get(""))
+	assert.Equal(http.StatusOK, get("Bearer t1"))
+}